@@ -3,6 +3,7 @@ package ui
 import (
 	"github.com/Yeicor/sdfx-ui/internal"
 	"github.com/deadsy/sdfx/sdf"
+	v3 "github.com/deadsy/sdfx/vec/v3"
 	"math"
 )
 
@@ -10,8 +11,9 @@ func (r *Renderer) newRendererState() *internal.RendererState {
 	r.implLock.RLock()
 	defer r.implLock.RUnlock()
 	s := &internal.RendererState{
-		ResInv: 4,
-		Bb:     toBox2(r.impl.BoundingBox()), // 100% zoom (will fix aspect ratio later)
+		ResInv:      4,
+		Progressive: true,
+		Bb:          toBox2(r.impl.BoundingBox()), // 100% zoom (will fix aspect ratio later)
 	}
 	resetCam3(s, r)
 	return s
@@ -21,9 +23,102 @@ func cam3MatrixNoTranslation(s *internal.RendererState) sdf.M44 {
 	return sdf.RotateZ(s.CamYaw).Mul(sdf.RotateX(s.CamPitch))
 }
 
+// cam3OrthoDist is the distance from the camera to CamCenter in orthographic mode. It is far enough to see the whole
+// surface from any angle, as CamDist only controls the extent of the view.
+func cam3OrthoDist(s *internal.RendererState, bb sdf.Box3) float64 {
+	return s.CamCenter.Sub(bb.Center()).Length() + bb.Size().Length()
+}
+
 func resetCam3(s *internal.RendererState, r *Renderer) {
 	s.CamCenter = r.impl.BoundingBox().Center()
 	s.CamDist = r.impl.BoundingBox().Size().Length() / 2
 	s.CamPitch = -math.Pi / 4 // Look from 45º up
 	s.CamYaw = -math.Pi / 4   // Look from 45º right
 }
+
+// View3 is a standard orientation of the SDF3 camera (see Opt3View).
+type View3 int
+
+const (
+	View3Front     View3 = iota // Looking at the Y- face
+	View3Back                   // Looking at the Y+ face
+	View3Left                   // Looking at the X- face
+	View3Right                  // Looking at the X+ face
+	View3Top                    // Looking at the Z+ face
+	View3Bottom                 // Looking at the Z- face
+	View3Isometric              // Looking at the X+, Y- and Z+ faces at the same angle
+)
+
+// camOffsetDir is the direction from the pivot to the camera, in the SDF3's coordinates.
+func (v View3) camOffsetDir() v3.Vec {
+	switch v {
+	case View3Back:
+		return v3.Vec{Y: 1}
+	case View3Left:
+		return v3.Vec{X: -1}
+	case View3Right:
+		return v3.Vec{X: 1}
+	case View3Top:
+		return v3.Vec{Z: 1}
+	case View3Bottom:
+		return v3.Vec{Z: -1}
+	case View3Isometric:
+		return v3.Vec{X: 1, Y: -1, Z: 1}.Normalize()
+	default:
+		return v3.Vec{Y: -1}
+	}
+}
+
+// viewCam3 snaps the camera to a standard view, framing the whole surface like resetCam3.
+func viewCam3(s *internal.RendererState, r *Renderer, view View3) {
+	s.CamCenter = r.impl.BoundingBox().Center()
+	s.CamDist = r.impl.BoundingBox().Size().Length() / 2
+	// The camera is at CamCenter + RotateZ(yaw) * RotateX(pitch) * (0, -CamDist, 0), where Z is inverted (see invertZ)
+	dir := view.camOffsetDir()
+	s.CamPitch = math.Max(-(math.Pi/2 - 1e-5), math.Min(math.Pi/2-1e-5, math.Asin(dir.Z)))
+	s.CamYaw = 0 // Keep X+ to the right for top and bottom views
+	if math.Abs(dir.X) > 1e-12 || math.Abs(dir.Y) > 1e-12 {
+		s.CamYaw = math.Atan2(dir.X, -dir.Y)
+	}
+}
+
+// clipModes3 are the section planes that can be cycled through interactively (see nextClipPlane3).
+var clipModes3 = []string{"off", "X", "Y", "Z", "view"}
+
+// nextClipPlane3 enables the section plane for the given mode (an index of clipModes3) through the camera's pivot,
+// hiding the half-space that is closer to the camera.
+func nextClipPlane3(s *internal.RendererState, mode int) {
+	camOffsetDir := cam3MatrixNoTranslation(s).MulPosition(v3.Vec{Y: -1})
+	var normal v3.Vec
+	switch clipModes3[mode] {
+	case "X":
+		normal = v3.Vec{X: 1}
+	case "Y":
+		normal = v3.Vec{Y: 1}
+	case "Z":
+		normal = v3.Vec{Z: 1}
+	case "view":
+		normal = camOffsetDir
+	}
+	if normal.Dot(camOffsetDir) < 0 {
+		normal = normal.Neg()
+	}
+	s.ClipPoint = s.CamCenter
+	s.ClipNormal = normal
+}
+
+// clipPlaneName describes the current section plane of the state.
+func clipPlaneName(s *internal.RendererState) string {
+	normal := s.ClipNormal.Abs()
+	switch {
+	case normal == v3.Vec{}:
+		return "off"
+	case normal.Y == 0 && normal.Z == 0:
+		return "X"
+	case normal.X == 0 && normal.Z == 0:
+		return "Y"
+	case normal.X == 0 && normal.Y == 0:
+		return "Z"
+	}
+	return "arbitrary"
+}
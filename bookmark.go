@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"github.com/Yeicor/sdfx-ui/internal"
+	"github.com/deadsy/sdfx/sdf"
+	v3 "github.com/deadsy/sdfx/vec/v3"
+	"strconv"
+)
+
+// maxBookmarks is the number of bookmark slots, recalled with the keys 1 to 9
+const maxBookmarks = 9
+
+// Bookmark is a named camera state that can be saved (Ctrl+<number>) and recalled (<number>) from the UI.
+// Only the fields for the type of SDF being rendered are used.
+type Bookmark struct {
+	Name string
+	// SDF2
+	Bb sdf.Box2
+	// SDF3
+	CamCenter                 v3.Vec
+	CamYaw, CamPitch, CamDist float64
+	CamOrtho                  bool
+}
+
+// OptMBookmarks predefines the camera bookmarks: the first one is recalled with key 1, the second one with key 2, etc.
+// Nil bookmarks leave the slot empty, and bookmarks after the 9th are ignored.
+// WARNING: Need to run again the main renderer to apply a change of this option.
+func OptMBookmarks(bookmarks ...*Bookmark) Option {
+	return func(r *Renderer) {
+		for i, bookmark := range bookmarks {
+			if i >= maxBookmarks {
+				break
+			}
+			r.bookmarks[i] = bookmark
+		}
+	}
+}
+
+// newBookmark captures the current camera of the given state
+func newBookmark(name string, s *internal.RendererState) *Bookmark {
+	return &Bookmark{
+		Name:      name,
+		Bb:        s.Bb,
+		CamCenter: s.CamCenter,
+		CamYaw:    s.CamYaw,
+		CamPitch:  s.CamPitch,
+		CamDist:   s.CamDist,
+		CamOrtho:  s.CamOrtho,
+	}
+}
+
+// apply moves the camera of the given state to the bookmark
+func (b *Bookmark) apply(s *internal.RendererState, dimensions int) {
+	switch dimensions {
+	case 2:
+		s.Bb = b.Bb
+	case 3:
+		s.CamCenter = b.CamCenter
+		s.CamYaw, s.CamPitch, s.CamDist = b.CamYaw, b.CamPitch, b.CamDist
+		s.CamOrtho = b.CamOrtho
+	}
+}
+
+// saveBookmark stores the current camera in the given slot (0-based), keeping the name of the previous bookmark.
+// It must be called with the implStateLock held.
+func (r *Renderer) saveBookmark(slot int) *Bookmark {
+	name := "Bookmark " + strconv.Itoa(slot+1)
+	if prev := r.bookmarks[slot]; prev != nil && prev.Name != "" {
+		name = prev.Name
+	}
+	r.bookmarks[slot] = newBookmark(name, r.implState)
+	r.bookmarksSaved[slot] = true
+	return r.bookmarks[slot]
+}
+
+// recallBookmark moves the camera to the bookmark in the given slot (0-based), returning nil if the slot is empty.
+// It must be called with the implStateLock held.
+func (r *Renderer) recallBookmark(slot int) *Bookmark {
+	bookmark := r.bookmarks[slot]
+	if bookmark != nil {
+		bookmark.apply(r.implState, r.implDimCache)
+	}
+	return bookmark
+}
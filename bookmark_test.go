@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"github.com/deadsy/sdfx/sdf"
+	v2 "github.com/deadsy/sdfx/vec/v2"
+	v3 "github.com/deadsy/sdfx/vec/v3"
+	"testing"
+)
+
+func TestRenderer_bookmarks3(t *testing.T) {
+	s, _ := sdf.Box3D(v3.Vec{X: 1, Y: 1, Z: 1}, 0.2)
+	predefined := &Bookmark{Name: "Corner", CamCenter: v3.Vec{X: 0.5, Y: 0.5, Z: 0.5}, CamDist: 1}
+	r := NewRenderer(s, OptMBookmarks(nil, predefined))
+	if r.recallBookmark(0) != nil {
+		t.Fatalf("expected an empty bookmark slot")
+	}
+	if r.recallBookmark(1) != predefined || r.implState.CamCenter != predefined.CamCenter || r.implState.CamDist != 1 {
+		t.Fatalf("expected the camera to move to the predefined bookmark")
+	}
+	r.implState.CamYaw = 1.5
+	if bookmark := r.saveBookmark(1); bookmark.Name != "Corner" || bookmark.CamYaw != 1.5 {
+		t.Fatalf("expected the bookmark to be overwritten keeping its name, got %#v", bookmark)
+	}
+	if bookmark := r.saveBookmark(2); bookmark.Name != "Bookmark 3" {
+		t.Fatalf("expected a default name for new bookmarks, got %s", bookmark.Name)
+	}
+	r.implState.CamYaw = 0
+	r.recallBookmark(2)
+	if r.implState.CamYaw != 1.5 {
+		t.Fatalf("expected the camera to move to the saved bookmark")
+	}
+}
+
+func TestRenderer_bookmarks2(t *testing.T) {
+	s := sdf.Box2D(v2.Vec{X: 1, Y: 1}, 0.25)
+	bb := sdf.Box2{Min: v2.Vec{X: -0.1, Y: -0.1}, Max: v2.Vec{X: 0.1, Y: 0.1}}
+	r := NewRenderer(s, OptMBookmarks(&Bookmark{Name: "Center", Bb: bb}))
+	r.recallBookmark(0)
+	if r.implState.Bb != bb {
+		t.Fatalf("expected the 2D camera to move to the bookmark")
+	}
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"log"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// childShutdownTimeout is how long a child may take to shut down gracefully when the renderer exits before being killed
+const childShutdownTimeout = 5 * time.Second
+
+// childProcess is a started child process (running the new code)
+type childProcess struct {
+	cmd    *exec.Cmd
+	exited <-chan struct{} // Closed when the process exits
+	client *rendererClient // The connection to the child, nil until it connects (protected by childProcesses.lock)
+}
+
+// childProcesses tracks all the child processes that are still running (including previous children that are shutting
+// down and new ones that did not connect yet), to be able to stop them all when the renderer exits.
+type childProcesses struct {
+	lock   sync.Mutex
+	set    map[*childProcess]struct{}
+	closed bool // Set when the renderer exits, no more children can be started
+}
+
+func newChildProcesses() *childProcesses {
+	return &childProcesses{set: map[*childProcess]struct{}{}}
+}
+
+// add tracks the new child process, returning false if the renderer is exiting (and the child should be killed)
+func (c *childProcesses) add(child *childProcess) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.closed {
+		return false
+	}
+	c.set[child] = struct{}{}
+	return true
+}
+
+// connected records the connection to the child, to be able to shut it down gracefully
+func (c *childProcesses) connected(child *childProcess, client *rendererClient) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	child.client = client
+}
+
+// remove stops tracking the child process (once it exited)
+func (c *childProcesses) remove(child *childProcess) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.set, child)
+}
+
+// shutdown stops all the child processes and prevents new ones from starting. Connected children are asked to shut down
+// gracefully and killed if they are still running after the timeout, while the rest are killed right away.
+func (c *childProcesses) shutdown(timeout time.Duration) {
+	c.lock.Lock()
+	c.closed = true
+	children := make(map[*childProcess]*rendererClient, len(c.set))
+	for child := range c.set {
+		children[child] = child.client
+	}
+	c.lock.Unlock()
+	wg := &sync.WaitGroup{}
+	for child, client := range children {
+		wg.Add(1)
+		go func(child *childProcess, client *rendererClient) {
+			defer wg.Done()
+			if client == nil { // Not connected yet, nothing to lose
+				if err := killChildProcess(child.cmd); err != nil {
+					log.Println("[DevRenderer] Child kill error:", err)
+				}
+				return
+			}
+			select {
+			case <-client.closed: // Already shutting down (e.g. a previous child)
+			default:
+				client.Stop()
+				if err := client.Shutdown(timeout); err != nil {
+					log.Println("[DevRenderer] Child shutdown error:", err)
+				}
+				_ = client.Close()
+			}
+			select {
+			case <-child.exited:
+			case <-time.After(timeout):
+				log.Println("[DevRenderer] Killing child process", child.cmd.Process.Pid, "(did not exit in time)")
+				if err := killChildProcess(child.cmd); err != nil {
+					log.Println("[DevRenderer] Child kill error:", err)
+				}
+			}
+		}(child, client)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"github.com/Yeicor/sdfx-ui/internal"
+	"github.com/deadsy/sdfx/sdf"
+	"net"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func Test_childProcesses_shutdown(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep command not available:", err)
+	}
+	children := newChildProcesses()
+	cmd := exec.Command("sleep", "60")
+	setChildProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	exited := make(chan struct{})
+	child := &childProcess{cmd: cmd, exited: exited}
+	go func() {
+		_ = cmd.Wait()
+		children.remove(child)
+		close(exited)
+	}()
+	if !children.add(child) {
+		t.Fatal("could not track the child process")
+	}
+	start := time.Now()
+	children.shutdown(time.Minute) // Not connected, so it should be killed right away
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the child process was not killed")
+	}
+	if took := time.Since(start); took > 5*time.Second {
+		t.Fatal("shutdown took too long:", took)
+	}
+	if children.add(&childProcess{cmd: cmd, exited: exited}) {
+		t.Fatal("new children should not be tracked after shutdown")
+	}
+}
+
+func TestRenderer_fallBack(t *testing.T) {
+	s, _ := sdf.Sphere3D(1)
+	r := NewRenderer(s)
+	newClient := func() *rendererClient { // Connected to the initial implementation
+		serverConn, clientConn := net.Pipe()
+		go internal.NewDevRendererService(r.initialImpl, make(chan os.Signal, 1)).ServeConn(serverConn)
+		client, err := newDevRendererClient(rpc.NewClient(clientConn), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = client.(*rendererClient).Close() })
+		return client.(*rendererClient)
+	}
+	first, second, third := newClient(), newClient(), newClient()
+	if toClose := r.swapImpl(first); toClose != nil {
+		t.Fatalf("the initial implementation must never be closed")
+	}
+	// A new child that did not pass the health check is closed when replaced, keeping the last working one
+	if toClose := r.swapImpl(second); toClose != first || r.fallbackImpl != r.initialImpl {
+		t.Fatalf("expected the unhealthy child to be closed, got %p", toClose)
+	}
+	if !r.markHealthy(second) || r.markHealthy(first) {
+		t.Fatalf("only the current child can pass the health check (once)")
+	}
+	// The last working child is kept as the fallback while the new one may crash
+	if toClose := r.swapImpl(third); toClose != nil || r.fallbackImpl != internal.DevRendererImpl(second) {
+		t.Fatalf("expected the healthy child to be kept as the fallback, got %p to close", toClose)
+	}
+	if !r.fallBack(third) || r.impl != internal.DevRendererImpl(second) || !r.implHealthy {
+		t.Fatalf("expected to fall back to the last working child")
+	}
+	if r.fallBack(third) {
+		t.Fatalf("the crashed child is no longer in use")
+	}
+	if !r.fallBack(second) || r.impl != r.initialImpl {
+		t.Fatalf("expected to fall back to the initial implementation as a last resort")
+	}
+}
@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// childStatus is the state of the latest child process (compiling and running the new code)
+type childStatus int
+
+const (
+	childStatusNone      childStatus = iota // No child process was started (e.g. no file changes yet)
+	childStatusBuilding                     // Compiling (and starting, for custom run commands) the new code
+	childStatusStarting                     // Starting the compiled code
+	childStatusConnected                    // Rendering the new code
+	childStatusFailed                       // The new code failed to compile or start (still rendering the previous one)
+	childStatusCrashed                      // The new code crashed or got stuck (rendering the last working code instead)
+)
+
+func (s childStatus) String() string {
+	switch s {
+	case childStatusBuilding:
+		return "building..."
+	case childStatusStarting:
+		return "starting..."
+	case childStatusConnected:
+		return "connected"
+	case childStatusFailed:
+		return "failed (still showing the previous code)"
+	case childStatusCrashed:
+		return "crashed (showing the last working code, save to try again)"
+	default:
+		return "none"
+	}
+}
+
+// maxChildErrorLines is the maximum number of lines of errors shown on screen
+const maxChildErrorLines = 10
+
+// maxChildTraceLines is the maximum number of lines of stack traces shown on screen
+const maxChildTraceLines = 20
+
+// maxChildOutput is the maximum number of bytes of the child's output that are kept for error reporting
+const maxChildOutput = 64 * 1024
+
+// buildErrorRegexp matches compiler errors like "./main.go:12:2: undefined: foo"
+var buildErrorRegexp = regexp.MustCompile(`^\S+\.go:\d+(:\d+)?: .+`)
+
+// childOutput keeps the tail of the output of a child process (safe for concurrent use)
+type childOutput struct {
+	lock sync.Mutex
+	buf  []byte
+}
+
+func (o *childOutput) Write(p []byte) (int, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.buf = append(o.buf, p...)
+	if len(o.buf) > maxChildOutput {
+		o.buf = o.buf[len(o.buf)-maxChildOutput:]
+	}
+	return len(p), nil
+}
+
+func (o *childOutput) String() string {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return string(o.buf)
+}
+
+// parseBuildErrors extracts the compiler errors (file:line: message) from the output of a failed child process.
+// If there are none (e.g. it panicked), the last lines of the output are returned instead.
+func parseBuildErrors(output string) []string {
+	var errs, lastLines []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if buildErrorRegexp.MatchString(line) {
+			errs = append(errs, line)
+		}
+		if strings.TrimSpace(line) != "" {
+			lastLines = append(lastLines, line)
+		}
+	}
+	if len(errs) == 0 {
+		if len(lastLines) > maxChildErrorLines {
+			lastLines = lastLines[len(lastLines)-maxChildErrorLines:]
+		}
+		return lastLines
+	}
+	if len(errs) > maxChildErrorLines {
+		errs = append(errs[:maxChildErrorLines-1], "... and "+strconv.Itoa(len(errs)-maxChildErrorLines+1)+" more errors")
+	}
+	return errs
+}
+
+// parseCrash extracts the panic message and stack trace from the output of a crashed child process.
+// If there are none, it behaves like parseBuildErrors.
+func parseCrash(output string) []string {
+	lines := strings.Split(output, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.HasPrefix(lines[i], "panic: ") || strings.HasPrefix(lines[i], "fatal error: ") {
+			return errorLines(strings.Join(lines[i:], "\n"), maxChildTraceLines)
+		}
+	}
+	return parseBuildErrors(output)
+}
+
+// errorLines splits a (multi-line) error message to be shown on screen, keeping at most maxLines lines.
+func errorLines(msg string, maxLines int) []string {
+	var res []string
+	for _, line := range strings.Split(msg, "\n") {
+		line = strings.ReplaceAll(strings.TrimRight(line, "\r"), "\t", "    ")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(res) == maxLines-1 {
+			res = append(res, "...")
+			break
+		}
+		res = append(res, line)
+	}
+	return res
+}
+
+// setChildStatus updates the status of the latest child process shown on screen.
+// The previous errors are kept while building and starting the new code, and only cleared once it connects.
+func (r *Renderer) setChildStatus(status childStatus, errs []string) {
+	r.implStateLock.Lock()
+	defer r.implStateLock.Unlock()
+	r.childStatus = status
+	if errs != nil || status != childStatusBuilding && status != childStatusStarting {
+		r.childErrors = errs
+	}
+}
+
+// setChildTimes records how long the latest child took to compile (0 if unknown) and to start, shown on screen
+func (r *Renderer) setChildTimes(buildTime, startTime time.Duration) {
+	r.implStateLock.Lock()
+	defer r.implStateLock.Unlock()
+	r.childBuildTime = buildTime
+	r.childStartTime = startTime
+}
+
+// formatChildTimes describes how long the latest child took to compile and start
+func formatChildTimes(buildTime, startTime time.Duration) string {
+	if buildTime == 0 { // Custom run command, compiling is part of starting
+		return "started in " + startTime.Round(time.Millisecond).String()
+	}
+	return "built in " + buildTime.Round(time.Millisecond).String() + ", started in " + startTime.Round(time.Millisecond).String()
+}
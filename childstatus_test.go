@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_parseBuildErrors(t *testing.T) {
+	output := "# github.com/Yeicor/sdfx-ui/examples/spiral\n" +
+		"./main.go:12:2: undefined: foo\n" +
+		"./main.go:15:9: cannot use x (variable of type int) as float64 value in argument to bar\n"
+	errs := parseBuildErrors(output)
+	if len(errs) != 2 || errs[0] != "./main.go:12:2: undefined: foo" {
+		t.Fatalf("unexpected compiler errors: %#v", errs)
+	}
+}
+
+func Test_parseBuildErrorsTooMany(t *testing.T) {
+	output := strings.Repeat("main.go:1:1: syntax error\n", maxChildErrorLines+5)
+	errs := parseBuildErrors(output)
+	if len(errs) != maxChildErrorLines || !strings.Contains(errs[len(errs)-1], "6 more errors") {
+		t.Fatalf("unexpected compiler errors: %#v", errs)
+	}
+}
+
+func Test_parseBuildErrorsPanic(t *testing.T) {
+	output := "panic: runtime error: invalid memory address or nil pointer dereference\n\ngoroutine 1 [running]:\n"
+	errs := parseBuildErrors(output)
+	if len(errs) != 2 || !strings.HasPrefix(errs[0], "panic:") {
+		t.Fatalf("expected the last lines of the output, got %#v", errs)
+	}
+}
+
+func Test_childOutput(t *testing.T) {
+	output := &childOutput{}
+	_, _ = output.Write([]byte(strings.Repeat("a", maxChildOutput)))
+	_, _ = output.Write([]byte("b"))
+	if s := output.String(); len(s) != maxChildOutput || !strings.HasSuffix(s, "ab") {
+		t.Fatalf("expected only the tail of the output to be kept")
+	}
+}
+
+func Test_parseCrash(t *testing.T) {
+	output := "[DevRenderer] Child service ready...\npanic: runtime error: index out of range [3] with length 3\n\n" +
+		"goroutine 7 [running]:\nmain.main.func1(...)\n\t/src/main.go:12 +0x1d\n"
+	errs := parseCrash(output)
+	if len(errs) != 4 || !strings.HasPrefix(errs[0], "panic: ") || strings.Contains(errs[3], "\t") {
+		t.Fatalf("unexpected crash report: %#v", errs)
+	}
+}
+
+func Test_errorLines(t *testing.T) {
+	errs := errorLines(strings.Repeat("line\n", maxChildTraceLines*2), maxChildTraceLines)
+	if len(errs) != maxChildTraceLines || errs[len(errs)-1] != "..." {
+		t.Fatalf("expected the lines to be limited, got %#v", errs)
+	}
+}
+
+func Test_formatChildTimes(t *testing.T) {
+	if got := formatChildTimes(1234*time.Millisecond, 56*time.Millisecond); got != "built in 1.234s, started in 56ms" {
+		t.Fatalf("unexpected times: %q", got)
+	}
+	if got := formatChildTimes(0, 2*time.Second); got != "started in 2s" {
+		t.Fatalf("unexpected times: %q", got)
+	}
+}
+
+func TestRenderer_setChildStatus(t *testing.T) {
+	r := &Renderer{implStateLock: &sync.RWMutex{}}
+	r.setChildStatus(childStatusFailed, []string{"main.go:1:1: syntax error"})
+	for _, status := range []childStatus{childStatusBuilding, childStatusStarting} {
+		r.setChildStatus(status, nil)
+		if len(r.childErrors) != 1 {
+			t.Fatalf("expected the previous errors to be kept while %s, got %#v", status, r.childErrors)
+		}
+	}
+	r.setChildStatus(childStatusConnected, nil)
+	if len(r.childErrors) != 0 {
+		t.Fatalf("expected the errors to be cleared once connected, got %#v", r.childErrors)
+	}
+}
@@ -3,7 +3,6 @@ package ui
 import (
 	"context"
 	"github.com/Yeicor/sdfx-ui/internal"
-	"github.com/deadsy/sdfx/sdf"
 	v2 "github.com/deadsy/sdfx/vec/v2"
 	"github.com/deadsy/sdfx/vec/v2i"
 	"github.com/hajimehoshi/ebiten"
@@ -72,8 +71,11 @@ func (r *Renderer) rerenderOpt(forceCancel bool, callbacks ...func(err error)) {
 			if !forceCancel {
 				return
 			}
-			r.implStateLock.RLock() // Avoid race condition with creating a new context
-			r.renderingCtxCancel()
+			// Avoid race condition with creating a new context (which is nil if only RenderImage was called yet)
+			r.implStateLock.RLock()
+			if r.renderingCtxCancel != nil {
+				r.renderingCtxCancel()
+			}
 			r.implStateLock.RUnlock()
 			r.renderingLock.Lock() // Wait for previous render to finish (should be very fast)
 		}
@@ -81,7 +83,7 @@ func (r *Renderer) rerenderOpt(forceCancel bool, callbacks ...func(err error)) {
 		var renderCtx context.Context
 		r.implStateLock.Lock()
 		renderCtx, r.renderingCtxCancel = context.WithCancel(context.Background())
-		renderSize := v2i.Vec{X: int(float64(r.screenSize.X) / float64(r.implState.ResInv)), Y: int(float64(r.screenSize.Y) / float64(r.implState.ResInv))}
+		renderSize := r.renderSize()
 		r.implStateLock.Unlock()
 		partialRenders := make(chan *image.RGBA)
 		r.goPartialRendersHandler(partialRenders, renderSize)
@@ -104,6 +106,7 @@ func (r *Renderer) rerenderOpt(forceCancel bool, callbacks ...func(err error)) {
 			}
 		}
 		r.implStateLock.RUnlock()
+		renderStats := r.stats.begin()
 		r.implLock.RLock()
 		err = r.impl.Render(&internal.RenderArgs{
 			Ctx:              renderCtx,
@@ -112,8 +115,17 @@ func (r *Renderer) rerenderOpt(forceCancel bool, callbacks ...func(err error)) {
 			CachedRenderLock: r.cachedRenderLock,
 			PartialRenders:   partialRenders,
 			FullRender:       r.cachedRenderCPU,
+			Stats:            renderStats,
 		})
 		r.implLock.RUnlock()
+		r.stats.end(err == nil)
+		r.implStateLock.Lock()
+		if err != nil && renderCtx.Err() == nil { // Show errors (like panics in the SDF) unless it was cancelled
+			r.renderErrors = errorLines(err.Error(), maxChildTraceLines)
+		} else if err == nil {
+			r.renderErrors = nil
+		}
+		r.implStateLock.Unlock()
 		if err != nil {
 			if err != context.Canceled {
 				log.Println("[DevRenderer] Error rendering:", err)
@@ -130,21 +142,20 @@ func (r *Renderer) rerenderOpt(forceCancel bool, callbacks ...func(err error)) {
 		r.implLock.RLock()
 		r.implStateLock.Lock()               // WARNING: Locking order (to avoid deadlocks)
 		r.implDimCache = r.impl.Dimensions() // Only updated here
-		if r.implDimCache == 2 {
-			r.cachedRenderBb2 = r.implState.Bb
-		} else {
-			r.cachedRenderBb2 = sdf.Box2{}
-		}
 		r.implStateLock.Unlock()
 		r.implLock.RUnlock()
 		r.cachedRenderLock.Lock()
 		// Need to resize the rendering result: overwrite
 		r.cachedRender = renderGpuImg
-		// TODO: reuse the previous render for the parts that did not change (SDF2 only)
 		r.cachedRenderLock.Unlock()
 	}(callbacks...)
 }
 
+// renderSize returns the size of the rendered images for the current screen size and resolution (implStateLock must be held)
+func (r *Renderer) renderSize() v2i.Vec {
+	return v2i.Vec{X: int(float64(r.screenSize.X) / float64(r.implState.ResInv)), Y: int(float64(r.screenSize.Y) / float64(r.implState.ResInv))}
+}
+
 func (r *Renderer) goPartialRendersHandler(partialRenders chan *image.RGBA, renderSize v2i.Vec) {
 	go func(renderSize v2i.Vec) {
 		partialRenderCopy := image.NewRGBA(image.Rect(0, 0, renderSize.X, renderSize.Y))
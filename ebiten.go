@@ -11,6 +11,9 @@ type rendererEbitenGame struct {
 }
 
 func (r rendererEbitenGame) Update(_ *ebiten.Image) error {
+	if r.exitRequested.Load() {
+		return errExitRequested // Stops ebiten.RunGame
+	}
 	var err error
 	r.cachedRenderLock.RLock()
 	firstFrame := r.cachedRender == nil
@@ -12,6 +12,7 @@ import (
 	"os"
 	"os/exec"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -33,7 +34,7 @@ import (
 // This also means that the resulting surface can be much more detailed (depending on chosen resolution)
 // than the triangle meshes generated by standard renderers.
 //
-// TODO: Once merged, use max-resolution runtime-computed VoxelSdf2 and VoxelSdf3 to accelerate camera movements
+// Camera movements around expensive SDF3s can be accelerated with a cache of distance samples (see Opt3VoxelCache).
 //
 // It uses [ebiten](https://github.com/hajimehoshi/ebiten) for rendering, which is cross-platform, so it could also
 // be used to showcase a surface (without automatic updates) creating an application for desktop, web or mobile.
@@ -45,7 +46,6 @@ type Renderer struct {
 	implStateLock       *sync.RWMutex            // the renderer's state lock
 	cachedRender        *ebiten.Image            // the latest cached render (to avoid rendering every frame, or frame parts even if nothing changed)
 	cachedRenderCPU     *image.RGBA              // the latest cached render (to avoid rendering every frame, or frame parts even if nothing changed)
-	cachedRenderBb2     sdf.Box2                 // what part of the SDF2 the latest cached render represents (not implemented, and no equivalent optimization available for SDF3s)
 	cachedPartialRender *ebiten.Image            // the latest partial render (to display render progress visually)
 	cachedRenderLock    *sync.RWMutex            // the lock over tha partial render
 	screenSize          v2i.Vec                  // the screen ResInv
@@ -53,13 +53,38 @@ type Renderer struct {
 	renderingLock       trylock.TryLocker        // locked when we are rendering, use renderingCtx to cancel the previous render
 	translateFrom       v2i.Vec                  // Translate/rotate (for 3D) screen space start
 	translateFromStop   v2i.Vec                  // Translate/rotate (for 3D) screen space end (recorded while processing the new frame)
+	bookmarks           [maxBookmarks]*Bookmark  // the camera bookmarks (protected by implStateLock)
+	bookmarksSaved      [maxBookmarks]bool       // the bookmark slots saved by the user, which are persisted (see saveState)
+	notice              string                   // a short message to show on screen (protected by implStateLock)
+	noticeUntil         time.Time                // when to stop showing the notice
+	clipMode            int                      // the last section plane selected with the keyboard (index of clipModes3)
+	childStatus         childStatus              // the status of the latest child process (protected by implStateLock)
+	childErrors         []string                 // the errors of the latest failed child process, shown on screen
+	childBuildTime      time.Duration            // how long the latest child took to compile (0 if unknown, protected by implStateLock)
+	childStartTime      time.Duration            // how long the latest child took to start and connect (protected by implStateLock)
+	renderErrors        []string                 // the errors of the latest render, shown on screen (protected by implStateLock)
+	initialImpl         internal.DevRendererImpl // the implementation compiled with the renderer (last resort fallback)
+	fallbackImpl        internal.DevRendererImpl // the last working implementation before impl (protected by implLock)
+	implHealthy         bool                     // whether impl passed the health check (protected by implLock)
+	children            *childProcesses          // the child processes that are still running, stopped on exit
+	stats               *renderStatsTracker      // the statistics of the latest interactive render
+	imageStats          *renderStatsTracker      // the statistics of the latest RenderImage
+	showStats           bool                     // whether to show the statistics of the latest render (protected by implStateLock)
+	exitRequested       *atomic.Bool             // set to stop the renderer as if the window was closed (e.g. on interrupt)
 	// Static configuration
-	runCmd             func() *exec.Cmd // generates a new command to compile and run the code for the new SDF
-	watchFiles         []string         // the files to watch for recompilation of new code
-	backOff            backoff.BackOff  // the backoff to connect to the new process after recompilation
-	partialRenderEvery time.Duration    // how much time to wait between partial render updates to screen
-	zoomFactor         float64          // how much to scale the SDF2/SDF3 on each zoom operation (> 1)
-	smoothCamera       bool             // whether to render while moving the camera (for 2D and 3D)
+	buildCmd           func(binaryPath string) *exec.Cmd // generates a new command to compile the code for the new SDF
+	runCmd             func() *exec.Cmd                  // generates a new command to compile and run the code (nil uses buildCmd)
+	watchFiles         []string                          // the files to watch for recompilation of new code
+	watchInclude       []string                          // the patterns of files inside watched directories that trigger a recompilation
+	watchExclude       []string                          // the patterns of files and directories that are never watched
+	stateFile          string                            // the file to save the state to on exit and restore it from on start ("" disables it)
+	backOff            backoff.BackOff                   // the backoff to connect to the new process after recompilation
+	compressFrames     bool                              // whether to compress the images sent by the new process
+	watchdogEvery      time.Duration                     // how often to check that the new process is still healthy
+	watchdogTimeout    time.Duration                     // how long the new process may take to respond or make rendering progress
+	partialRenderEvery time.Duration                     // how much time to wait between partial render updates to screen
+	zoomFactor         float64                           // how much to scale the SDF2/SDF3 on each zoom operation (> 1)
+	smoothCamera       bool                              // whether to render while moving the camera (for 2D and 3D)
 }
 
 // NewRenderer see Renderer
@@ -69,27 +94,38 @@ func NewRenderer(anySDF interface{}, opts ...Option) *Renderer {
 		implStateLock:     &sync.RWMutex{},
 		cachedRenderLock:  &sync.RWMutex{},
 		renderingLock:     trylock.New(),
+		children:          newChildProcesses(),
+		exitRequested:     &atomic.Bool{},
+		stats:             &renderStatsTracker{},
+		imageStats:        &renderStatsTracker{},
 		translateFrom:     v2i.Vec{math.MaxInt, math.MaxInt},
 		translateFromStop: v2i.Vec{math.MaxInt, math.MaxInt},
 		// Configuration
-		runCmd: func() *exec.Cmd {
-			return exec.Command("go", "run", "-v", ".")
+		buildCmd: func(binaryPath string) *exec.Cmd {
+			return exec.Command("go", "build", "-o", binaryPath, ".")
 		},
 		watchFiles:         []string{"."},
+		watchInclude:       defaultWatchInclude,
+		watchExclude:       defaultWatchExclude,
+		stateFile:          defaultStateFile,
 		backOff:            backoff.NewExponentialBackOff(),
 		partialRenderEvery: time.Second,
 		zoomFactor:         1.25,
+		watchdogEvery:      2 * time.Second,
+		watchdogTimeout:    time.Minute,
 	}
 	r.backOff.(*backoff.ExponentialBackOff).InitialInterval = 10 * time.Millisecond
 	switch s := anySDF.(type) {
 	case sdf.SDF2:
 		r.impl = newDevRenderer2(s)
-		r.cachedRenderBb2 = s.BoundingBox()
 	case sdf.SDF3:
 		r.impl = newDevRenderer3(s)
 	default:
 		panic("anySDF must be either a SDF2 or a SDF3")
 	}
+	r.initialImpl = r.impl
+	r.fallbackImpl = r.impl
+	r.implHealthy = true
 	r.implDimCache = r.impl.Dimensions()
 	r.implState = r.newRendererState()
 	r.implState.ReflectTree = r.impl.ReflectTree() // Compute the reflection-based tree once on load and cache it
@@ -102,8 +138,14 @@ func NewRenderer(anySDF interface{}, opts ...Option) *Renderer {
 
 const requestedAddressEnvKey = "SDFX_DEV_RENDERER_CHILD"
 
-// Run starts the UI or connects to a previous UI renderer and provides the new surface automatically
+// Run starts the UI or connects to a previous UI renderer and provides the new surface automatically.
+// If the SDFX_UI_SNAPSHOT environment variable is set, it renders the SDF to that PNG file and returns instead
+// (the virtual screen size may be set with SDFX_UI_SNAPSHOT_SIZE=<width>x<height>, defaults to 1920x1080), using only
+// the state configured by the options (the state saved by the last session is ignored, see OptMStateFile).
 func (r *Renderer) Run() error {
+	if snapshotPath := os.Getenv(snapshotEnvKey); snapshotPath != "" { // Render to a file and exit (no window)
+		return r.runSnapshot(snapshotPath)
+	}
 	requestedAddress := os.Getenv(requestedAddressEnvKey)
 	if requestedAddress != "" { // Found a parent renderer (environment variable)
 		return r.runChild(requestedAddress)
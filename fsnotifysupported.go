@@ -5,6 +5,9 @@ package ui
 
 import "github.com/fsnotify/fsnotify"
 
+// fsCreate is the operation of a new file or directory being created
+const fsCreate = fsnotify.Create
+
 func newFsWatcher() (*fsnotify.Watcher, error) {
 	return fsnotify.NewWatcher()
 }
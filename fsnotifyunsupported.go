@@ -16,6 +16,9 @@ type fsEvent struct {
 // fsOp describes a set of file operations.
 type fsOp uint32
 
+// fsCreate is the operation of a new file or directory being created
+const fsCreate fsOp = 1
+
 var errFsNotifyNotSupported = errors.New("fsnotify is not supported for this platform")
 
 // Watcher watches a set of files, delivering events to a channel.
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"github.com/Yeicor/sdfx-ui/internal"
+	"github.com/barkimedes/go-deepcopy"
+	"image"
+	"sync"
+)
+
+// RenderImage renders the SDF offscreen to a new image of the given size, without opening a window.
+// It uses the current camera and color mode (configured through options like Opt2Cam, Opt3Cam or OptMColorMode), and
+// extra options may be given to override them for this render only (the renderer's state is not modified).
+// The statistics of the render are available afterwards through Renderer.ImageStats.
+// The ResInv setting is ignored, as the image is always rendered at the requested resolution.
+// It waits for the interactive render in progress (if any) to finish, and the next ones wait for it.
+//
+// Options that configure the implementation instead of the state (like Opt3Mesh or Opt3CamFov) will also affect all
+// future renders.
+//
+// NOTE: Ebiten is still initialized when this package is imported, so desktop platforms may need a (virtual) display.
+func (r *Renderer) RenderImage(ctx context.Context, width, height int, opts ...Option) (*image.RGBA, error) {
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("image size must be positive")
+	}
+	// Work on a copy of the state, to avoid modifying the interactive renderer
+	r.implStateLock.RLock()
+	rCopy := *r
+	rCopy.implState = deepcopy.MustAnything(r.implState).(*internal.RendererState)
+	r.implStateLock.RUnlock()
+	for _, opt := range opts {
+		opt(&rCopy)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	// The implementations keep scratch state between renders (and remote ones only render one image at a time), so wait
+	// for the interactive render in progress to finish, and delay the next ones until this one is done
+	if !r.renderingLock.TryLock(ctx) {
+		return nil, ctx.Err()
+	}
+	defer r.renderingLock.Unlock()
+	renderStats := r.imageStats.begin() // Also available for benchmarks (see Renderer.ImageStats)
+	r.implLock.RLock()
+	defer r.implLock.RUnlock()
+	err := r.impl.Render(&internal.RenderArgs{
+		Ctx:              ctx,
+		State:            rCopy.implState,
+		StateLock:        &sync.RWMutex{},
+		CachedRenderLock: &sync.RWMutex{},
+		FullRender:       img,
+		Stats:            renderStats,
+		Headless:         true,
+	})
+	r.imageStats.end(err == nil)
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"context"
+	"github.com/deadsy/sdfx/sdf"
+	v2 "github.com/deadsy/sdfx/vec/v2"
+	"github.com/deadsy/sdfx/vec/v2i"
+	v3 "github.com/deadsy/sdfx/vec/v3"
+	"github.com/hajimehoshi/ebiten"
+	"testing"
+)
+
+func TestRenderer_RenderImage2(t *testing.T) {
+	s := sdf.Box2D(v2.Vec{X: 1, Y: 1}, 0.25)
+	r := NewRenderer(s, Opt2Cam(sdf.Box2{Min: v2.Vec{X: -1, Y: -1}, Max: v2.Vec{X: 1, Y: 1}}))
+	bbBefore := r.implState.Bb
+	img, err := r.RenderImage(context.Background(), 64, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 64 || img.Bounds().Dy() != 32 {
+		t.Fatalf("unexpected image size %v", img.Bounds())
+	}
+	if img.RGBAAt(32, 16) == img.RGBAAt(0, 0) {
+		t.Fatalf("expected the surface to be visible at the center of the image")
+	}
+	if r.implState.Bb != bbBefore {
+		t.Fatalf("the renderer state should not be modified by RenderImage")
+	}
+}
+
+func TestRenderer_RenderImage3(t *testing.T) {
+	s, _ := sdf.Box3D(v3.Vec{X: 1, Y: 1, Z: 1}, 0.2)
+	r := NewRenderer(s)
+	img, err := r.RenderImage(context.Background(), 32, 32, OptMColorMode(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.RGBAAt(16, 16) == img.RGBAAt(0, 0) {
+		t.Fatalf("expected the surface to be visible at the center of the image")
+	}
+	if r.implState.ColorMode != 0 {
+		t.Fatalf("options given to RenderImage should not modify the renderer state")
+	}
+}
+
+func TestRenderer_RenderImageInvalidSize(t *testing.T) {
+	s, _ := sdf.Box3D(v3.Vec{X: 1, Y: 1, Z: 1}, 0.2)
+	if _, err := NewRenderer(s).RenderImage(context.Background(), 0, 10); err == nil {
+		t.Fatalf("expected an error for an empty image")
+	}
+}
+
+func TestRenderer_RenderImageWhileRendering(t *testing.T) {
+	s, _ := sdf.Sphere3D(1)
+	r := NewRenderer(s)
+	r.implState.DrawBbs = true // Also uses the depth buffer
+	r.screenSize = v2i.Vec{X: 96, Y: 64}
+	var err error
+	if r.cachedRender, err = ebiten.NewImage(1, 1, ebiten.FilterDefault); err != nil {
+		t.Fatal(err)
+	}
+	r.cachedPartialRender = r.cachedRender
+	for i := 0; i < 3; i++ {
+		rendered := make(chan error, 1)
+		r.rerender(func(err error) { rendered <- err })
+		img, err := r.RenderImage(context.Background(), 40+i, 30) // A different size than the interactive render
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img.Bounds().Dx() != 40+i {
+			t.Fatalf("unexpected image size %v", img.Bounds())
+		}
+		if err = <-rendered; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"image/color"
 	"image/color/palette"
 	"math"
+	"sync"
 )
 
 //-----------------------------------------------------------------------------
@@ -63,6 +64,11 @@ type renderer2 struct {
 	evalMin, evalMax float64  // The pre-computed minimum and maximum of the whole surface (for stable colors and speed)
 	evalScanCells    v2i.Vec
 	getBBColor       func(idx int) color.Color
+	// The latest complete render (without bounding boxes), reused when panning to only render the newly exposed pixels
+	prevRender          *image.RGBA
+	prevRenderBb        sdf.Box2
+	prevRenderColorMode int
+	prevRenderLock      sync.Mutex // Protects the previous render (see reusePrevRender)
 }
 
 func newDevRenderer2(s sdf.SDF2) internal.DevRendererImpl {
@@ -82,7 +88,7 @@ func (r *renderer2) Dimensions() int {
 
 func (r *renderer2) BoundingBox() sdf.Box3 {
 	bb := r.s.BoundingBox()
-	return sdf.Box3{Min: v3.Vec{X: bb.Min.X, Y: bb.Max.Y, Z: 0.}, Max: v3.Vec{X: bb.Max.X, Y: bb.Max.Y, Z: 0.}}
+	return sdf.Box3{Min: v3.Vec{X: bb.Min.X, Y: bb.Min.Y, Z: 0.}, Max: v3.Vec{X: bb.Max.X, Y: bb.Max.Y, Z: 0.}}
 }
 
 func (r *renderer2) ReflectTree() *internal.ReflectTree {
@@ -125,18 +131,36 @@ func (r *renderer2) Render(args *internal.RenderArgs) error {
 	}
 
 	// Perform the actual render
-	err := implCommonRender(func(pixel v2i.Vec, pixel01 v2.Vec) interface{} { return nil },
-		func(pixel v2i.Vec, pixel01 v2.Vec, job interface{}) *jobResult {
-			pixel01.Y = 1 - pixel01.Y // Inverted Y
-			args.StateLock.RLock()
-			pos := args.State.Bb.Min.Add(pixel01.Mul(args.State.Bb.Size()))
-			args.StateLock.RUnlock()
-			grayVal := imageColor2(r.s.Evaluate(pos), evalMin, evalMax)
-			return &jobResult{
-				pixel: pixel,
-				color: color.RGBA{R: uint8(grayVal * 255), G: uint8(grayVal * 255), B: uint8(grayVal * 255), A: 255},
-			}
-		}, args, &r.pixelsRand)
+	args.StateLock.RLock()
+	bb, colorMode := args.State.Bb, args.State.ColorMode
+	args.StateLock.RUnlock()
+	renderPixel := func(pixel v2i.Vec, pixel01 v2.Vec) color.RGBA {
+		pixel01.Y = 1 - pixel01.Y // Inverted Y
+		pos := bb.Min.Add(pixel01.Mul(bb.Size()))
+		grayVal := imageColor2(r.s.Evaluate(pos), evalMin, evalMax)
+		return color.RGBA{R: uint8(grayVal * 255), G: uint8(grayVal * 255), B: uint8(grayVal * 255), A: 255}
+	}
+	var err error
+	if pixels, ok := r.reusePrevRender(args, bb, colorMode); ok { // Only render the newly exposed pixels
+		args.Stats.Start(len(pixels))
+		err = implCommonRenderPixels(renderPixel, args, pixels, false)
+		if args.PartialRenders != nil {
+			close(args.PartialRenders)
+		}
+	} else {
+		err = implCommonRender(renderPixel, args, &r.pixelsRand)
+	}
+	if err == nil && !args.Headless { // Remember this render for the next one (before drawing the bounding boxes over it)
+		r.prevRenderLock.Lock()
+		if r.prevRender == nil || r.prevRender.Rect != args.FullRender.Rect {
+			r.prevRender = image.NewRGBA(args.FullRender.Rect)
+		}
+		args.CachedRenderLock.RLock()
+		copy(r.prevRender.Pix, args.FullRender.Pix)
+		args.CachedRenderLock.RUnlock()
+		r.prevRenderBb, r.prevRenderColorMode = bb, colorMode
+		r.prevRenderLock.Unlock()
+	}
 
 	if err == nil && args.State.DrawBbs {
 		// Draw bounding boxes over the image
@@ -156,6 +180,62 @@ func (r *renderer2) Render(args *internal.RenderArgs) error {
 	return err
 }
 
+// panShift returns the offset in pixels of a new render of bb (with the given size) with respect to the previous render,
+// if the view was only translated by whole pixels, so that the overlapping part of the previous render can be reused.
+// It must be called with prevRenderLock held.
+func (r *renderer2) panShift(bb sdf.Box2, size image.Point, colorMode int) (v2i.Vec, bool) {
+	if r.prevRender == nil || r.prevRender.Rect.Size() != size || r.prevRenderColorMode != colorMode {
+		return v2i.Vec{}, false
+	}
+	bbSize, prevBbSize := bb.Size(), r.prevRenderBb.Size()
+	if math.Abs(bbSize.X-prevBbSize.X) > 1e-9*bbSize.X || math.Abs(bbSize.Y-prevBbSize.Y) > 1e-9*bbSize.Y {
+		return v2i.Vec{}, false // Zoomed
+	}
+	shift := bb.Min.Sub(r.prevRenderBb.Min).Div(bbSize).Mul(v2.Vec{X: float64(size.X), Y: float64(size.Y)})
+	shiftPixels := v2i.Vec{X: int(math.Round(shift.X)), Y: int(math.Round(shift.Y))}
+	if math.Abs(shift.X-float64(shiftPixels.X)) > 1e-3 || math.Abs(shift.Y-float64(shiftPixels.Y)) > 1e-3 {
+		return v2i.Vec{}, false // Not aligned to the pixels of the previous render
+	}
+	if shiftPixels.X <= -size.X || shiftPixels.X >= size.X || shiftPixels.Y <= -size.Y || shiftPixels.Y >= size.Y {
+		return v2i.Vec{}, false // Nothing to reuse
+	}
+	return shiftPixels, true
+}
+
+// reusePrevRender copies the previous render into args.FullRender if the view was only panned (see panShift), returning
+// the newly exposed pixels that still need to be rendered. Headless renders never reuse the previous render.
+func (r *renderer2) reusePrevRender(args *internal.RenderArgs, bb sdf.Box2, colorMode int) ([]int, bool) {
+	if args.Headless {
+		return nil, false
+	}
+	r.prevRenderLock.Lock()
+	defer r.prevRenderLock.Unlock()
+	size := args.FullRender.Rect.Size()
+	shift, ok := r.panShift(bb, size, colorMode)
+	if !ok {
+		return nil, false
+	}
+	var pixels []int
+	args.CachedRenderLock.Lock()
+	for y := 0; y < size.Y; y++ {
+		prevY := y - shift.Y // Inverted Y
+		for x := 0; x < size.X; x++ {
+			prevX := x + shift.X
+			if prevX < 0 || prevX >= size.X || prevY < 0 || prevY >= size.Y {
+				pixels = append(pixels, y*size.X+x)
+			}
+		}
+		if prevY >= 0 && prevY < size.Y { // Copy the overlapping part of the row
+			dstX, srcX := max(0, -shift.X), max(0, shift.X)
+			n := size.X - max(shift.X, -shift.X)
+			copy(args.FullRender.Pix[args.FullRender.PixOffset(dstX, y):][:4*n],
+				r.prevRender.Pix[r.prevRender.PixOffset(srcX, prevY):][:4*n])
+		}
+	}
+	args.CachedRenderLock.Unlock()
+	return pixels, true
+}
+
 // imageColor2 returns the grayscale color for the returned SDF2.Evaluate value, given the reference minimum and maximum
 // SDF2.Evaluate values. The returned value is in the range [0, 1].
 func imageColor2(dist, dmin, dmax float64) float64 {
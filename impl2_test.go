@@ -4,7 +4,11 @@ import (
 	"context"
 	"github.com/Yeicor/sdfx-ui/internal"
 	"github.com/deadsy/sdfx/sdf"
+	v2 "github.com/deadsy/sdfx/vec/v2"
+	v3 "github.com/deadsy/sdfx/vec/v3"
 	"image"
+	"math"
+	"reflect"
 	"sync"
 	"testing"
 )
@@ -28,3 +32,54 @@ func BenchmarkDevRenderer2_Render(b *testing.B) {
 		}
 	}
 }
+
+func TestDevRenderer2_RenderPan(t *testing.T) {
+	s, _ := sdf.Circle2D(1)
+	renderHeadless := func(impl internal.DevRendererImpl, bb sdf.Box2, headless bool) (*image.RGBA, int64) {
+		stats := &internal.RenderStats{}
+		img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+		err := impl.Render(&internal.RenderArgs{Ctx: context.Background(), State: &internal.RendererState{Bb: bb},
+			StateLock: &sync.RWMutex{}, CachedRenderLock: &sync.RWMutex{}, FullRender: img, Stats: stats, Headless: headless})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return img, stats.Snapshot().Pixels
+	}
+	render := func(impl internal.DevRendererImpl, bb sdf.Box2) (*image.RGBA, int64) {
+		return renderHeadless(impl, bb, false)
+	}
+	bb := sdf.Box2{Min: v2.Vec{X: -2, Y: -1.5}, Max: v2.Vec{X: 2, Y: 1.5}}
+	pixelSize := bb.Size().X / 64
+	impl := newDevRenderer2(s)
+	render(impl, bb)
+	for _, shift := range []v2.Vec{{X: 5, Y: -3}, {X: -20, Y: 0}, {X: 0, Y: 0}} {
+		bb = bb.Translate(shift.MulScalar(pixelSize))
+		got, rendered := render(impl, bb)
+		expected, _ := render(newDevRenderer2(s), bb)
+		if !reflect.DeepEqual(got.Pix, expected.Pix) {
+			t.Fatalf("shift %v: the reused render does not match a full render", shift)
+		}
+		expectedRendered := int64(64*48 - (64-math.Abs(shift.X))*(48-math.Abs(shift.Y)))
+		if rendered != expectedRendered {
+			t.Fatalf("shift %v: expected to render %d pixels, rendered %d", shift, expectedRendered, rendered)
+		}
+	}
+	// Headless renders neither reuse the previous render nor replace it
+	if _, rendered := renderHeadless(impl, bb.Translate(v2.Vec{X: pixelSize}), true); rendered != 64*48 {
+		t.Fatalf("expected a full headless render, rendered %d pixels", rendered)
+	}
+	if _, rendered := render(impl, bb.Translate(v2.Vec{Y: pixelSize})); rendered != 64 {
+		t.Fatalf("expected to reuse the previous interactive render, rendered %d pixels", rendered)
+	}
+	if _, rendered := render(impl, bb.ScaleAboutCenter(0.5)); rendered != 64*48 { // Zooming renders everything again
+		t.Fatalf("expected a full render after zooming, rendered %d pixels", rendered)
+	}
+}
+
+func TestDevRenderer2_BoundingBox(t *testing.T) {
+	s := sdf.Transform2D(sdf.Box2D(v2.Vec{X: 2, Y: 1}, 0), sdf.Translate2d(v2.Vec{X: 3, Y: 4}))
+	bb := newDevRenderer2(s).BoundingBox()
+	if bb.Min != (v3.Vec{X: 2, Y: 3.5}) || bb.Max != (v3.Vec{X: 4, Y: 4.5}) {
+		t.Fatalf("expected the bounding box of the SDF2 (at Z=0), got %v", bb)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"image/color"
 	"image/color/palette"
 	"math"
+	"sync"
+	"sync/atomic"
 )
 
 //-----------------------------------------------------------------------------
@@ -36,6 +38,49 @@ func Opt3Cam(camCenter v3.Vec, pitch, yaw, dist float64) Option {
 	}
 }
 
+// Opt3View sets the default camera to one of the standard views, framing the whole surface.
+// WARNING: Need to run again the main renderer to apply a change of this option.
+func Opt3View(view View3) Option {
+	return func(r *Renderer) {
+		viewCam3(r.implState, r, view)
+	}
+}
+
+// Opt3Ortho sets the default projection of the camera to orthographic (or back to perspective).
+// In orthographic mode, zooming changes the extent of the view instead of the distance to the camera's pivot.
+// WARNING: Need to run again the main renderer to apply a change of this option.
+func Opt3Ortho(ortho bool) Option {
+	return func(r *Renderer) {
+		r.implState.CamOrtho = ortho
+	}
+}
+
+// Opt3Clip sets the default section plane, which hides the half-space in front of point in the direction of normal
+// (a zero normal disables it). Both are given in the same coordinates as the camera (see Opt3Cam).
+// WARNING: Need to run again the main renderer to apply a change of this option.
+func Opt3Clip(point, normal v3.Vec) Option {
+	return func(r *Renderer) {
+		r.implState.ClipPoint = point
+		if normal != (v3.Vec{}) {
+			normal = normal.Normalize()
+		}
+		r.implState.ClipNormal = normal
+	}
+}
+
+// Opt3Supersample sets the default anti-aliasing quality: samples x samples jittered rays for each pixel (1: disabled).
+// If adaptive, a fast render with a single ray per pixel is shown first, and then only the pixels that differ from their
+// neighbors (silhouettes, sharp edges...) are refined. Otherwise, all pixels are supersampled in a single slower pass.
+// The diagnostic color modes (2 and 3) are never supersampled, as averaging them would hide the per-ray values.
+func Opt3Supersample(samples int, adaptive bool) Option {
+	return func(r *Renderer) {
+		r.implState.Supersample = samples
+		if r3, ok := r.impl.(*renderer3); ok {
+			r3.supersampleAdaptive = adaptive
+		}
+	}
+}
+
 // Opt3CamFov sets the default Field Of View for the camera (default 90º, in radians).
 func Opt3CamFov(fov float64) Option {
 	return func(r *Renderer) {
@@ -48,6 +93,7 @@ func Opt3CamFov(fov float64) Option {
 // Opt3RayConfig sets the configuration for the raycast (balancing performance and quality).
 // Rendering a pink pixel means that the ray reached maxSteps without hitting the surface or reaching the limit
 // (consider increasing maxSteps (reduce performance), increasing epsilon or increasing stepScale (both reduce quality)).
+// The raymarching steps and distance color modes (2 and 3, see OptMColorMode) show where these parameters matter.
 func Opt3RayConfig(scaleAndSigmoid, stepScale, epsilon float64, maxSteps int) Option {
 	return func(r *Renderer) {
 		if r3, ok := r.impl.(*renderer3); ok {
@@ -70,6 +116,15 @@ func Opt3Colors(surface, background, error color.RGBA) Option {
 	}
 }
 
+// Opt3CutColor changes the color of the faces cut by the section plane (see Opt3Clip), which are drawn hatched.
+func Opt3CutColor(cut color.RGBA) Option {
+	return func(r *Renderer) {
+		if r3, ok := r.impl.(*renderer3); ok {
+			r3.cutColor = cut
+		}
+	}
+}
+
 // Opt3NormalEps sets the distance between samples used to compute the normals.
 func Opt3NormalEps(normalEps float64) Option {
 	return func(r *Renderer) {
@@ -107,6 +162,7 @@ type renderer3 struct {
 	pixelsRand                                []int    // Cached set of pixels in random order to avoid shuffling (reset on recompilation and resolution changes)
 	camFOV                                    float64  // The Field Of View (X axis) for the camera
 	surfaceColor, backgroundColor, errorColor color.RGBA
+	cutColor                                  color.RGBA // The color of the faces cut by the section plane
 	normalEps                                 float64
 	lightDir                                  v3.Vec // The light's direction for ColorMode: true (simple simulation based on normals)
 	depthBuffer                               []float64
@@ -116,23 +172,32 @@ type renderer3 struct {
 	rayScaleAndSigmoid, rayStepScale, rayEpsilon float64
 	rayMaxSteps                                  int
 
+	supersampleAdaptive bool // Anti-aliasing only refines the edges of a fast render (see Opt3Supersample)
+
+	// Voxel cache to accelerate raycasting (see Opt3VoxelCache)
+	voxelResolution int
+	voxelCacheOnce  sync.Once
+	voxelCacheReady atomic.Pointer[voxelCache3]
+
 	meshRenderer *renderer3mesh // Alternative renderer
 }
 
 func newDevRenderer3(s sdf.SDF3) internal.DevRendererImpl {
 	r := &renderer3{
-		s:                  &invertZ{s}, // TODO: fix rendering to use Z+ (instead of Z-) as UP instead of this hack.
-		camFOV:             math.Pi / 2, // 90º FOV-X
-		surfaceColor:       color.RGBA{R: 255 - 20, G: 255 - 40, B: 255 - 80, A: 255},
-		backgroundColor:    color.RGBA{R: 50, G: 100, B: 150, A: 255},
-		errorColor:         color.RGBA{R: 255, B: 255, A: 255},
-		normalEps:          1e-6,
-		lightDir:           v3.Vec{X: -1, Y: 1, Z: 1}.Normalize(), // Same as default camera TODO: Follow camera mode?
-		rayScaleAndSigmoid: 0,
-		rayStepScale:       1,
-		rayEpsilon:         1e-2,
-		rayMaxSteps:        100,
-		meshRenderer:       &renderer3mesh{},
+		s:                   &invertZ{s}, // TODO: fix rendering to use Z+ (instead of Z-) as UP instead of this hack.
+		camFOV:              math.Pi / 2, // 90º FOV-X
+		surfaceColor:        color.RGBA{R: 255 - 20, G: 255 - 40, B: 255 - 80, A: 255},
+		backgroundColor:     color.RGBA{R: 50, G: 100, B: 150, A: 255},
+		errorColor:          color.RGBA{R: 255, B: 255, A: 255},
+		cutColor:            color.RGBA{R: 220, G: 60, B: 60, A: 255},
+		normalEps:           1e-6,
+		lightDir:            v3.Vec{X: -1, Y: 1, Z: 1}.Normalize(), // Same as default camera TODO: Follow camera mode?
+		rayScaleAndSigmoid:  0,
+		rayStepScale:        1,
+		rayEpsilon:          1e-2,
+		rayMaxSteps:         100,
+		meshRenderer:        &renderer3mesh{},
+		supersampleAdaptive: true,
 		getBBColor: func(idx int) color.Color {
 			return palette.WebSafe[((idx + 1) % len(palette.WebSafe))]
 		},
@@ -154,12 +219,14 @@ func (r *renderer3) ReflectTree() *internal.ReflectTree {
 
 func (r *renderer3) ColorModes() int {
 	// Use alternative renderer instead if configured to do so
-	if r.meshRenderer != nil {
+	if r.meshRenderer != nil && r.meshRenderer.mesh != nil {
 		return r.meshRenderer.ColorModes()
 	}
 	// 0: Constant color with basic shading (2 lights and no projected shadows)
 	// 1: Normal XYZ as RGB
-	return 2
+	// 2: Raymarching steps as a heatmap (colorMode3Steps)
+	// 3: Distance to the surface where each ray stopped (colorMode3Distance)
+	return 4
 }
 
 func (r *renderer3) Render(args *internal.RenderArgs) error {
@@ -172,22 +239,44 @@ func (r *renderer3) Render(args *internal.RenderArgs) error {
 	// Compute camera matrix and more (once per render)
 	args.StateLock.RLock()
 	colorModeCopy := args.State.ColorMode
+	supersample := args.State.Supersample
+	if colorModeCopy == colorMode3Steps || colorModeCopy == colorMode3Distance {
+		supersample = 1
+	}
 	bounds := args.FullRender.Bounds()
 	boundsSize := v2i.Vec{bounds.Size().X, bounds.Size().Y}
 	//aspectRatio := float64(boundsSize[0]) / float64(boundsSize.Y)
 	camViewMatrix := cam3MatrixNoTranslation(args.State)
-	camPos := args.State.CamCenter.Add(camViewMatrix.MulPosition(v3.Vec{Y: -args.State.CamDist}))
-	camDir := args.State.CamCenter.Sub(camPos).Normalize()
 	camFovX := r.camFOV
 	camFovY := 2 * math.Atan(math.Tan(camFovX/2) /**aspectRatio*/)
-	// Approximate max ray length for the whole camera (it could be improved... or maybe a fixed value is better)
 	sBb := r.BoundingBox()
-	maxRay := math.Abs(collideRayBb(camPos, camDir, sBb))
-	// If we do not hit the box (in a straight line, set a default -- box size, as following condition will be true)
-	if !sBb.Contains(camPos) { // If we hit from the outside of the box, add the whole size of the box
-		maxRay += sBb.Size().Length()
+	var camPos v3.Vec
+	var maxRay, camOrthoHalfSize float64
+	if args.State.CamOrtho {
+		// Parallel rays start from a plane behind the whole surface, with the same extent that the perspective camera
+		// would see at the pivot point
+		camOrthoDist := cam3OrthoDist(args.State, sBb)
+		camPos = args.State.CamCenter.Add(camViewMatrix.MulPosition(v3.Vec{Y: -camOrthoDist}))
+		camOrthoHalfSize = args.State.CamDist * math.Tan(camFovY/2)
+		maxRay = 2 * camOrthoDist
+	} else {
+		camPos = args.State.CamCenter.Add(camViewMatrix.MulPosition(v3.Vec{Y: -args.State.CamDist}))
+		// Approximate max ray length for the whole camera (it could be improved... or maybe a fixed value is better)
+		maxRay = math.Abs(collideRayBb(camPos, args.State.CamCenter.Sub(camPos).Normalize(), sBb))
+		// If we do not hit the box (in a straight line, set a default -- box size, as following condition will be true)
+		if !sBb.Contains(camPos) { // If we hit from the outside of the box, add the whole size of the box
+			maxRay += sBb.Size().Length()
+		}
+		maxRay *= 4 // Rays thrown from the camera at different angles may need a little more maxRay
+	}
+	camDir := args.State.CamCenter.Sub(camPos).Normalize()
+	// The section plane is applied as the intersection with a half-space
+	var s sdf.SDF3 = r.s
+	var clip *clipPlane3
+	if args.State.ClipNormal != (v3.Vec{}) {
+		clip = &clipPlane3{impl: r.s, point: args.State.ClipPoint, normal: args.State.ClipNormal.Normalize()}
+		s = clip
 	}
-	maxRay *= 4 // Rays thrown from the camera at different angles may need a little more maxRay
 
 	if args.State.DrawBbs {
 		// Reset internal depth buffer
@@ -205,30 +294,46 @@ func (r *renderer3) Render(args *internal.RenderArgs) error {
 
 	// Perform the actual render
 	camHalfFov := v2.Vec{X: camFovX, Y: camFovY}.DivScalar(2)
-	err := implCommonRender(func(pixel v2i.Vec, pixel01 v2.Vec) interface{} {
-		return &pixelRender{
-			pixel:         pixel,
-			bounds:        boundsSize,
-			camPos:        camPos,
-			camDir:        camDir,
-			camViewMatrix: camViewMatrix,
-			camHalfFov:    camHalfFov,
-			maxRay:        maxRay,
-			color:         colorModeCopy,
-			rendered:      color.RGBA{},
-		}
-	}, func(pixel v2i.Vec, pixel01 v2.Vec, job interface{}) *jobResult {
-		return &jobResult{
-			pixel: pixel,
-			color: r.samplePixel(pixel01, job.(*pixelRender)),
-		}
-	}, args, &r.pixelsRand)
+	job := &pixelRender{ // Shared by all pixels (read-only)
+		bounds:        boundsSize,
+		camPos:        camPos,
+		camDir:        camDir,
+		camViewMatrix: camViewMatrix,
+		camHalfFov:    camHalfFov,
+		camOrthoHalf:  camOrthoHalfSize,
+		maxRay:        maxRay,
+		s:             s,
+		clip:          clip,
+		voxels:        r.voxelCache(),
+		stats:         args.Stats,
+		color:         colorModeCopy,
+	}
+	samplePixel := func(pixel v2i.Vec, pixel01 v2.Vec) color.RGBA {
+		return r.samplePixel(pixel, pixel01, job)
+	}
+	supersamplePixel := func(pixel v2i.Vec, pixel01 v2.Vec) color.RGBA {
+		return r.supersamplePixel(pixel, pixel01, job, supersample)
+	}
+	var err error
+	switch {
+	case supersample <= 1:
+		err = implCommonRender(samplePixel, args, &r.pixelsRand)
+	case r.supersampleAdaptive: // Fast render first, then refine the edges
+		err = implCommonRenderRefined(samplePixel, supersamplePixel, args, &r.pixelsRand, func(img *image.RGBA) []int {
+			return edgePixels(img, supersampleEdgeThreshold)
+		})
+	default:
+		err = implCommonRender(supersamplePixel, args, &r.pixelsRand)
+	}
 
 	if err == nil && args.State.DrawBbs {
 		// FIXME: Assumes perfectly matching cameras (between both 3D renderers),
 		//  but they differ (in aspect ratio <--> FoV, matching on square windows)
 		r.renderBbs(args, r.depthBuffer)
 	}
+	if err == nil && !args.Headless { // Headless renders (images, snapshots and tests) only show the surface
+		r.renderLegend(args, colorModeCopy)
+	}
 
 	return err
 }
@@ -269,23 +374,28 @@ func (r *renderer3) renderBbs(args *internal.RenderArgs, depthBuffer []float64)
 	}
 }
 
+// pixelRender holds the parameters of a render that are needed to sample each pixel
 type pixelRender struct {
 	// CAMERA RELATED
-	pixel, bounds  v2i.Vec // Pixel and bounds for pixel
+	bounds         v2i.Vec // The size of the image
 	camPos, camDir v3.Vec  // Camera parameters
 	camViewMatrix  sdf.M44 // The world to camera matrix
 	camHalfFov     v2.Vec  // Camera's field of view
+	camOrthoHalf   float64 // Half of the vertical extent of the view for orthographic cameras (0 for perspective)
 	maxRay         float64 // The maximum distance of a ray (camPos, camDir) before getting out of bounds
+	// SURFACE
+	s      sdf.SDF3     // The SDF to raycast (r.s, possibly clipped)
+	clip   *clipPlane3  // The section plane (nil if disabled)
+	voxels *voxelCache3 // The cache of r.s to accelerate raycasting (nil if disabled or not ready)
 	// MISC
+	stats *internal.RenderStats // Where to count the raymarching work (may be nil)
 	color int
-	// OUTPUT
-	rendered color.RGBA
 }
 
-func (r *renderer3) samplePixel(pixel01 v2.Vec, job *pixelRender) color.RGBA {
+func (r *renderer3) samplePixel(pixel v2i.Vec, pixel01 v2.Vec, job *pixelRender) color.RGBA {
 	depthBufferIndex := -1
 	if len(r.depthBuffer) > 0 {
-		depthBufferIndex = job.pixel.Y*job.bounds.X + job.pixel.X
+		depthBufferIndex = pixel.Y*job.bounds.X + pixel.X
 	}
 	// Generate the ray for this pixel using the given camera parameters
 	rayFrom := job.camPos
@@ -293,21 +403,51 @@ func (r *renderer3) samplePixel(pixel01 v2.Vec, job *pixelRender) color.RGBA {
 	rayDirXZBase := pixel01.MulScalar(2).SubScalar(1)
 	rayDirXZBase.Y = -rayDirXZBase.Y
 	rayDirXZBase.X *= float64(job.bounds.X) / float64(job.bounds.Y) // Apply aspect ratio (again)
-	// Convert to the projection over a displacement of 1
-	rayDirXZBase = rayDirXZBase.Mul(v2.Vec{X: math.Tan(job.camHalfFov.X), Y: math.Tan(job.camHalfFov.Y)})
-	rayDir := v3.Vec{X: rayDirXZBase.X, Y: 1, Z: rayDirXZBase.Y} // Z is UP (and this default camera is X-right Y-up)
-	// Apply the camera matrix to the default ray
-	rayDir = job.camViewMatrix.MulPosition(rayDir) // .Normalize() (done in Raycast already)
-	// TODO: Orthogonal camera mode?
+	var rayDir v3.Vec
+	if job.camOrthoHalf > 0 { // Orthographic: parallel rays, starting from the pixel's position on the camera plane
+		rayFromOffset := rayDirXZBase.MulScalar(job.camOrthoHalf)
+		rayFrom = rayFrom.Add(job.camViewMatrix.MulPosition(v3.Vec{X: rayFromOffset.X, Z: rayFromOffset.Y}))
+		rayDir = job.camDir
+	} else {
+		// Convert to the projection over a displacement of 1
+		rayDirXZBase = rayDirXZBase.Mul(v2.Vec{X: math.Tan(job.camHalfFov.X), Y: math.Tan(job.camHalfFov.Y)})
+		rayDir = v3.Vec{X: rayDirXZBase.X, Y: 1, Z: rayDirXZBase.Y} // Z is UP (and this default camera is X-right Y-up)
+		// Apply the camera matrix to the default ray
+		rayDir = job.camViewMatrix.MulPosition(rayDir) // .Normalize() (done in Raycast already)
+	}
 
 	// Query the surface with the given ray
-	hit, t, steps := sdf.Raycast3(r.s, rayFrom, rayDir, r.rayScaleAndSigmoid, r.rayStepScale, r.rayEpsilon, job.maxRay, r.rayMaxSteps)
+	var hit v3.Vec
+	var t float64
+	var steps int
+	if job.voxels != nil {
+		hit, t, steps = job.voxels.raycast(job.s, rayFrom, rayDir, r.rayScaleAndSigmoid, r.rayStepScale, r.rayEpsilon, job.maxRay, r.rayMaxSteps)
+	} else {
+		hit, t, steps = sdf.Raycast3(job.s, rayFrom, rayDir, r.rayScaleAndSigmoid, r.rayStepScale, r.rayEpsilon, job.maxRay, r.rayMaxSteps)
+	}
+	job.stats.AddRay(steps, t >= 0, t < 0 && steps == r.rayMaxSteps)
+	if len(r.depthBuffer) > 0 { // HACK: Depth function similar to fauxgl (but not the same)
+		if t >= 0 {
+			r.depthBuffer[depthBufferIndex] = 1 / (1 + math.Exp(-t/10))
+		} else {
+			r.depthBuffer[depthBufferIndex] = math.MaxFloat64
+		}
+	}
+	if job.color == colorMode3Steps && steps < r.rayMaxSteps { // How hard it was to hit (or miss) the surface
+		return heatmapColor(float64(steps) / float64(r.rayMaxSteps))
+	}
 	// Convert the possible hit to a color
 	if t >= 0 { // Hit the surface
-		if len(r.depthBuffer) > 0 { // HACK: Depth function similar to fauxgl (but not the same)
-			r.depthBuffer[depthBufferIndex] = 1 / (1 + math.Exp(-t/10))
+		if job.color == colorMode3Distance { // How close to the surface the ray stopped (negative if it went inside)
+			return divergingColor(job.s.Evaluate(hit) / r.rayEpsilon)
 		}
-		normal := sdf.Normal3(r.s, hit, r.normalEps)
+		if job.clip != nil && job.clip.isCut(hit) { // Hatched cut face (the pattern is fixed on screen)
+			if (pixel.X+pixel.Y)/4%2 == 0 {
+				return r.cutColor
+			}
+			return color.RGBA{R: r.cutColor.R / 2, G: r.cutColor.G / 2, B: r.cutColor.B / 2, A: r.cutColor.A}
+		}
+		normal := sdf.Normal3(job.s, hit, r.normalEps)
 		if job.color == 0 { // Basic lighting + constant color
 			lightIntensity := math.Abs(normal.Dot(r.lightDir)) // Actually also simulating the opposite light
 			// If this was a performant ray-tracer, we could bounce the light
@@ -324,10 +464,7 @@ func (r *renderer3) samplePixel(pixel01 v2.Vec, job *pixelRender) color.RGBA {
 			B: uint8(math.Abs(normal.Z) * 255),
 			A: 255,
 		}
-	} else // Otherwise, missed the surface (or run out of steps)
-	if len(r.depthBuffer) > 0 {
-		r.depthBuffer[depthBufferIndex] = math.MaxFloat64
-	}
+	} // Otherwise, missed the surface (or run out of steps)
 	if steps == r.rayMaxSteps {
 		// Reached the maximum amount of steps (should change parameters)
 		return r.errorColor
@@ -375,6 +512,25 @@ func collideRayBb(origin v3.Vec, dir v3.Vec, bb sdf.Box3) float64 {
 	return tmin
 }
 
+// clipPlane3 is the intersection of a surface with the half-space behind a plane (used as a section plane).
+type clipPlane3 struct {
+	impl          sdf.SDF3
+	point, normal v3.Vec // The plane (normal must be normalized and points towards the removed half-space)
+}
+
+func (c *clipPlane3) Evaluate(p v3.Vec) float64 {
+	return math.Max(c.impl.Evaluate(p), p.Sub(c.point).Dot(c.normal))
+}
+
+func (c *clipPlane3) BoundingBox() sdf.Box3 {
+	return c.impl.BoundingBox()
+}
+
+// isCut returns true if the (surface) point lies on the face created by the plane instead of the original surface.
+func (c *clipPlane3) isCut(p v3.Vec) bool {
+	return p.Sub(c.point).Dot(c.normal) >= c.impl.Evaluate(p)
+}
+
 type swapYZ struct {
 	impl sdf.SDF3
 }
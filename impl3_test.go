@@ -3,8 +3,10 @@ package ui
 import (
 	"context"
 	"github.com/Yeicor/sdfx-ui/internal"
+	"github.com/deadsy/sdfx/render"
 	"github.com/deadsy/sdfx/sdf"
 	v3 "github.com/deadsy/sdfx/vec/v3"
+	"github.com/fogleman/fauxgl"
 	"image"
 	"math"
 	"sync"
@@ -124,3 +126,53 @@ func Test_collideRayBb(t *testing.T) {
 		})
 	}
 }
+
+func Test_viewCam3(t *testing.T) {
+	s, _ := sdf.Box3D(v3.Vec{X: 1, Y: 2, Z: 3}, 0.1)
+	r := NewRenderer(s)
+	for view := View3Front; view <= View3Isometric; view++ {
+		viewCam3(r.implState, r, view)
+		camOffset := cam3MatrixNoTranslation(r.implState).MulPosition(v3.Vec{Y: -1})
+		camOffset.Z = -camOffset.Z // Undo invertZ
+		if camOffset.Sub(view.camOffsetDir()).Length() > 1e-4 {
+			t.Errorf("view %d: camera offset %v, want %v", view, camOffset, view.camOffsetDir())
+		}
+		if r.implState.CamCenter != r.impl.BoundingBox().Center() {
+			t.Errorf("view %d: camera should be centered on the surface", view)
+		}
+	}
+}
+
+func Test_clipPlane3(t *testing.T) {
+	s, _ := sdf.Box3D(v3.Vec{X: 2, Y: 2, Z: 2}, 0)
+	clip := &clipPlane3{impl: s, point: v3.Vec{}, normal: v3.Vec{X: 1}}
+	if d := clip.Evaluate(v3.Vec{X: 0.5}); d != 0.5 {
+		t.Errorf("expected the hidden half-space to be outside (0.5), got %v", d)
+	}
+	if d := clip.Evaluate(v3.Vec{X: -0.5}); d != -0.5 {
+		t.Errorf("expected the visible half to be unmodified (-0.5), got %v", d)
+	}
+	if !clip.isCut(v3.Vec{X: 0, Y: 0.5}) {
+		t.Errorf("expected the point to be on the cut face")
+	}
+	if clip.isCut(v3.Vec{X: -1, Y: 0.5}) {
+		t.Errorf("expected the point to be on the original surface")
+	}
+}
+
+func Test_r3mClipTriangle(t *testing.T) {
+	tri := r3mConvertTriangle(&render.Triangle3{V: [3]v3.Vec{{X: -1}, {X: 1}, {X: -1, Y: 1}}})
+	point, normal := fauxgl.Vector{}, fauxgl.Vector{X: 1}
+	if res := r3mClipTriangle(tri, point, normal); len(res) != 2 {
+		t.Errorf("expected a quad (2 triangles) after cutting a vertex, got %d triangles", len(res))
+	}
+	if res := r3mClipTriangle(tri, point, normal.Negate()); len(res) != 1 {
+		t.Errorf("expected a single triangle after cutting two vertices, got %d triangles", len(res))
+	}
+	if res := r3mClipTriangle(tri, fauxgl.Vector{X: 2}, normal); len(res) != 1 || res[0] != tri {
+		t.Errorf("expected the original triangle when it is fully behind the plane")
+	}
+	if res := r3mClipTriangle(tri, fauxgl.Vector{X: -2}, normal); len(res) != 0 {
+		t.Errorf("expected no triangles when it is fully in front of the plane")
+	}
+}
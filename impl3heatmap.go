@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"github.com/Yeicor/sdfx-ui/internal"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+)
+
+// Diagnostic color modes of the raycasting renderer (see renderer3.ColorModes)
+const (
+	colorMode3Steps    = 2 // Raymarching steps of each pixel, to find the parts of the SDF that make rays crawl
+	colorMode3Distance = 3 // Final distance to the surface (in epsilons) of each hit, to find overshooting rays
+)
+
+// heatmapStops are the colors of the heatmap, from cold (0) to hot (1)
+var heatmapStops = []color.RGBA{
+	{R: 0, G: 0, B: 96, A: 255},
+	{R: 0, G: 64, B: 255, A: 255},
+	{R: 0, G: 224, B: 224, A: 255},
+	{R: 64, G: 255, B: 0, A: 255},
+	{R: 255, G: 224, B: 0, A: 255},
+	{R: 255, G: 0, B: 0, A: 255},
+}
+
+// heatmapColor maps a value from 0 to 1 (clamped) to the heatmap colors
+func heatmapColor(v float64) color.RGBA {
+	v = math.Max(0, math.Min(1, v)) * float64(len(heatmapStops)-1)
+	i := int(v)
+	if i == len(heatmapStops)-1 {
+		return heatmapStops[i]
+	}
+	return lerpRGBA(heatmapStops[i], heatmapStops[i+1], v-float64(i))
+}
+
+// divergingColor maps a value from -1 to 1 (clamped) to blue (negative), white (0) and red (positive)
+func divergingColor(v float64) color.RGBA {
+	v = math.Max(-1, math.Min(1, v))
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	if v < 0 {
+		return lerpRGBA(white, color.RGBA{R: 0, G: 64, B: 255, A: 255}, -v)
+	}
+	return lerpRGBA(white, color.RGBA{R: 255, G: 0, B: 0, A: 255}, v)
+}
+
+func lerpRGBA(from, to color.RGBA, t float64) color.RGBA {
+	lerp := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+	}
+	return color.RGBA{R: lerp(from.R, to.R), G: lerp(from.G, to.G), B: lerp(from.B, to.B), A: lerp(from.A, to.A)}
+}
+
+// renderLegend draws the scale of the diagnostic color modes on the bottom right corner of the image (for the UI)
+func (r *renderer3) renderLegend(args *internal.RenderArgs, colorMode int) {
+	var title, minLabel, maxLabel string
+	var scale func(v float64) color.RGBA
+	switch colorMode {
+	case colorMode3Steps:
+		title, minLabel, maxLabel = "Raymarching steps", "0", strconv.Itoa(r.rayMaxSteps)
+		scale = heatmapColor
+	case colorMode3Distance:
+		title, minLabel, maxLabel = "Distance at hit (< 0: inside)", "-eps", "+eps"
+		scale = func(v float64) color.RGBA { return divergingColor(2*v - 1) }
+	default:
+		return
+	}
+	const margin, lineHeight = 8, 16
+	img := args.FullRender
+	width := int(math.Min(256, float64(img.Rect.Dx()-2*margin)))
+	panel := image.Rect(img.Rect.Max.X-width-margin, img.Rect.Max.Y-4*lineHeight-margin, img.Rect.Max.X-margin, img.Rect.Max.Y-margin)
+	if width < 64 || panel.Min.Y < img.Rect.Min.Y {
+		return // Too small to fit
+	}
+	args.CachedRenderLock.Lock()
+	defer args.CachedRenderLock.Unlock()
+	for y := panel.Min.Y; y < panel.Max.Y; y++ { // Darken the background to make the text readable
+		for x := panel.Min.X; x < panel.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			img.SetRGBA(x, y, color.RGBA{R: c.R / 4, G: c.G / 4, B: c.B / 4, A: 255})
+		}
+	}
+	drawer := &font.Drawer{Dst: img, Src: image.White, Face: defaultFont}
+	drawText := func(msg string, x, y int) {
+		drawer.Dot = fixed.P(x, y)
+		drawer.DrawString(msg)
+	}
+	inner := panel.Inset(4)
+	drawText(title, inner.Min.X, inner.Min.Y+lineHeight-4)
+	barY := inner.Min.Y + lineHeight
+	for x := inner.Min.X; x < inner.Max.X; x++ {
+		c := scale(float64(x-inner.Min.X) / float64(inner.Dx()-1))
+		for y := barY; y < barY+lineHeight/2; y++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	labelsY := barY + lineHeight/2 + lineHeight - 2
+	drawText(minLabel, inner.Min.X, labelsY)
+	drawText(maxLabel, inner.Max.X-drawer.MeasureString(maxLabel).Round(), labelsY)
+	// The rays that ran out of steps are shown with the error color, not on the scale
+	for y := labelsY + 6; y < labelsY+14; y++ {
+		for x := inner.Min.X; x < inner.Min.X+8; x++ {
+			img.SetRGBA(x, y, r.errorColor)
+		}
+	}
+	drawText("out of steps", inner.Min.X+12, labelsY+14)
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"context"
+	"github.com/Yeicor/sdfx-ui/internal"
+	"github.com/deadsy/sdfx/sdf"
+	v3 "github.com/deadsy/sdfx/vec/v3"
+	"image/color"
+	"sync"
+	"testing"
+)
+
+func Test_heatmapColor(t *testing.T) {
+	if heatmapColor(-1) != heatmapStops[0] || heatmapColor(0) != heatmapStops[0] {
+		t.Fatal("cold values should use the first color")
+	}
+	if heatmapColor(1) != heatmapStops[len(heatmapStops)-1] || heatmapColor(2) != heatmapStops[len(heatmapStops)-1] {
+		t.Fatal("hot values should use the last color")
+	}
+	if divergingColor(0) != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) || divergingColor(1).B != 0 || divergingColor(-1).R != 0 {
+		t.Fatal("unexpected diverging colors")
+	}
+}
+
+func TestRenderer_RenderImageSteps(t *testing.T) {
+	s, _ := sdf.Box3D(v3.Vec{X: 1, Y: 1, Z: 1}, 0.2)
+	r := NewRenderer(s, OptMColorMode(colorMode3Steps))
+	if r.implState.ColorMode != colorMode3Steps {
+		t.Fatalf("the steps color mode should be available, got %d", r.implState.ColorMode)
+	}
+	img, err := r.RenderImage(context.Background(), 320, 240)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r3 := r.impl.(*renderer3)
+	center, corner := img.RGBAAt(160, 120), img.RGBAAt(0, 0)
+	if center == r3.surfaceColor || center == r3.backgroundColor || corner == r3.backgroundColor {
+		t.Fatalf("expected heatmap colors, got %v at the center and %v at the corner", center, corner)
+	}
+	if legend := img.RGBAAt(319-8-2, 239-8-2); legend.R <= 64 && legend.G <= 64 && legend.B <= 64 {
+		t.Fatalf("expected no legend on headless renders, got %v at the bottom right corner", legend)
+	}
+	// The interactive renders show the legend
+	err = r3.Render(&internal.RenderArgs{Ctx: context.Background(), State: r.implState, StateLock: &sync.RWMutex{},
+		CachedRenderLock: &sync.RWMutex{}, FullRender: img})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if legend := img.RGBAAt(319-8-2, 239-8-2); legend.R > 64 || legend.G > 64 || legend.B > 64 {
+		t.Fatalf("expected the darkened legend panel at the bottom right corner, got %v", legend)
+	}
+}
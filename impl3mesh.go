@@ -51,6 +51,9 @@ func Opt3Mesh(meshGenerator render.Render3, smoothNormalsRadians float64) Option
 type renderer3mesh struct {
 	mesh        *fauxgl.Mesh // the pre-compiled mesh to render
 	lastContext *fauxgl.Context
+	// The last mesh cut by the section plane (cached as it only changes when the plane is moved)
+	clippedMesh                 *fauxgl.Mesh
+	clippedPoint, clippedNormal v3.Vec
 }
 
 func (rm *renderer3mesh) ColorModes() int {
@@ -62,6 +65,21 @@ func (rm *renderer3mesh) ColorModes() int {
 
 func (rm *renderer3mesh) Render(r *renderer3, args *internal.RenderArgs) error {
 	camFauxglMatrix, camPos := rm.reset(r, args)
+	pixelCount := args.FullRender.Bounds().Dx() * args.FullRender.Bounds().Dy()
+	args.Stats.Start(pixelCount)
+	args.Stats.SetNoProgress() // A single (possibly slow) draw call, which must not be considered stuck
+
+	// Cut the mesh with the section plane (if enabled), showing the inside of the surface as there is no cut face
+	mesh := rm.mesh
+	args.StateLock.RLock()
+	clipPoint, clipNormal := args.State.ClipPoint, args.State.ClipNormal
+	args.StateLock.RUnlock()
+	if clipNormal != (v3.Vec{}) {
+		mesh = rm.clip(clipPoint, clipNormal.Normalize())
+		rm.lastContext.Cull = fauxgl.CullNone
+	} else {
+		rm.lastContext.Cull = fauxgl.CullBack
+	}
 
 	// Configure the shader (based on ColorMode)
 	if args.State.ColorMode == 0 {
@@ -76,13 +94,14 @@ func (rm *renderer3mesh) Render(r *renderer3, args *internal.RenderArgs) error {
 		rm.lastContext.Wireframe = args.State.ColorMode == 2 // set to wireframe mode
 	}
 	// Perform the actual render
-	rm.lastContext.DrawMesh(rm.mesh) // This is already multithread, no need to parallelize anymore
+	rm.lastContext.DrawMesh(mesh) // This is already multithread, no need to parallelize anymore
 	img := rm.lastContext.Image()
 
 	// Copy output full render (no partial renders supported)
 	args.CachedRenderLock.Lock()
 	copy(args.FullRender.Pix[args.FullRender.PixOffset(0, 0):], img.(*image.NRGBA).Pix[img.(*image.NRGBA).PixOffset(0, 0):])
 	args.CachedRenderLock.Unlock()
+	args.Stats.AddPixelsDone(pixelCount)
 
 	if args.State.DrawBbs {
 		// Draw bounding boxes over the image
@@ -116,20 +135,32 @@ func (rm *renderer3mesh) reset(r *renderer3, args *internal.RenderArgs) (fauxgl.
 	//args.state.CamCenter.Y = -args.state.CamCenter.Y
 	aspectRatio := float64(boundsSize.X) / float64(boundsSize.Y)
 	camViewMatrix := cam3MatrixNoTranslation(args.State)
-	camPos := args.State.CamCenter.Add(camViewMatrix.MulPosition(v3.Vec{Y: -args.State.CamDist / 1.12 /* Adjust to other implementation*/}))
-	camDir := args.State.CamCenter.Sub(camPos).Normalize()
 	camFovX := r.camFOV
-	camFovY := 2 * math.Atan(math.Tan(camFovX/2)*aspectRatio)
-	// Approximate max ray length for the whole camera (it could be improved... or maybe a fixed value is better)
 	sBb := r.BoundingBox()
-	maxRay := math.Abs(collideRayBb(camPos, camDir, sBb))
-	// If we do not hit the box (in a straight line, set a default -- box size, as following condition will be true)
-	if !sBb.Contains(camPos) { // If we hit from the outside of the box, add the whole size of the box
-		maxRay += sBb.Size().Length()
+	var camPos v3.Vec
+	var camFauxglMatrix fauxgl.Matrix
+	if args.State.CamOrtho {
+		// Same extent as the raycast renderer: what the perspective camera would see at the pivot point
+		camOrthoDist := cam3OrthoDist(args.State, sBb)
+		camPos = args.State.CamCenter.Add(camViewMatrix.MulPosition(v3.Vec{Y: -camOrthoDist}))
+		camOrthoHalfY := args.State.CamDist * math.Tan(camFovX/2)
+		camOrthoHalfX := camOrthoHalfY * aspectRatio
+		camFauxglMatrix = fauxgl.LookAt(r3mToFauxglVector(camPos), r3mToFauxglVector(args.State.CamCenter), fauxgl.Vector{Z: 1}).
+			Orthographic(-camOrthoHalfX, camOrthoHalfX, -camOrthoHalfY, camOrthoHalfY, 0, 2*camOrthoDist)
+	} else {
+		camPos = args.State.CamCenter.Add(camViewMatrix.MulPosition(v3.Vec{Y: -args.State.CamDist / 1.12 /* Adjust to other implementation*/}))
+		camDir := args.State.CamCenter.Sub(camPos).Normalize()
+		camFovY := 2 * math.Atan(math.Tan(camFovX/2)*aspectRatio)
+		// Approximate max ray length for the whole camera (it could be improved... or maybe a fixed value is better)
+		maxRay := math.Abs(collideRayBb(camPos, camDir, sBb))
+		// If we do not hit the box (in a straight line, set a default -- box size, as following condition will be true)
+		if !sBb.Contains(camPos) { // If we hit from the outside of the box, add the whole size of the box
+			maxRay += sBb.Size().Length()
+		}
+		maxRay *= 4 // Rays thrown from the camera at different angles may need a little more maxRay
+		camFauxglMatrix = fauxgl.LookAt(r3mToFauxglVector(camPos), r3mToFauxglVector(args.State.CamCenter), fauxgl.Vector{Z: 1}).
+			Perspective(camFovY*180/math.Pi, aspectRatio, 1e-6, maxRay)
 	}
-	maxRay *= 4 // Rays thrown from the camera at different angles may need a little more maxRay
-	camFauxglMatrix := fauxgl.LookAt(r3mToFauxglVector(camPos), r3mToFauxglVector(args.State.CamCenter), fauxgl.Vector{Z: 1}).
-		Perspective(camFovY*180/math.Pi, aspectRatio, 1e-6, maxRay)
 	//args.state.CamYaw -= math.Pi // HACK (restore)
 	//args.state.CamCenter.X = -args.state.CamCenter.X
 	//args.state.CamCenter.Y = -args.state.CamCenter.Y
@@ -137,6 +168,19 @@ func (rm *renderer3mesh) reset(r *renderer3, args *internal.RenderArgs) (fauxgl.
 	return camFauxglMatrix, camPos
 }
 
+// clip returns the part of the mesh behind the given plane
+func (rm *renderer3mesh) clip(point, normal v3.Vec) *fauxgl.Mesh {
+	if rm.clippedMesh == nil || rm.clippedPoint != point || rm.clippedNormal != normal {
+		var triangles []*fauxgl.Triangle
+		for _, tri := range rm.mesh.Triangles {
+			triangles = append(triangles, r3mClipTriangle(tri, r3mToFauxglVector(point), r3mToFauxglVector(normal))...)
+		}
+		rm.clippedMesh = fauxgl.NewTriangleMesh(triangles)
+		rm.clippedPoint, rm.clippedNormal = point, normal
+	}
+	return rm.clippedMesh
+}
+
 func (rm *renderer3mesh) depthBuffer() []float64 {
 	return rm.lastContext.DepthBuffer
 }
@@ -166,6 +210,37 @@ func r3mConvertTriangle(tri *render.Triangle3) *fauxgl.Triangle {
 	}
 }
 
+// r3mClipTriangle returns the part of the triangle behind the plane (0, 1 or 2 triangles).
+func r3mClipTriangle(tri *fauxgl.Triangle, point, normal fauxgl.Vector) []*fauxgl.Triangle {
+	vertices := [3]fauxgl.Vertex{tri.V1, tri.V2, tri.V3}
+	var kept []fauxgl.Vertex
+	for i, v := range vertices { // Sutherland-Hodgman against a single plane
+		next := vertices[(i+1)%3]
+		dist := v.Position.Sub(point).Dot(normal)
+		nextDist := next.Position.Sub(point).Dot(normal)
+		if dist <= 0 {
+			kept = append(kept, v)
+		}
+		if (dist <= 0) != (nextDist <= 0) {
+			t := dist / (dist - nextDist)
+			kept = append(kept, fauxgl.Vertex{
+				Position: v.Position.Lerp(next.Position, t),
+				Normal:   v.Normal.Lerp(next.Normal, t).Normalize(),
+				Texture:  v.Texture.Lerp(next.Texture, t),
+				Color:    v.Color.Lerp(next.Color, t),
+			})
+		}
+	}
+	if len(kept) == 3 && kept[0] == tri.V1 && kept[1] == tri.V2 && kept[2] == tri.V3 {
+		return []*fauxgl.Triangle{tri} // Fully behind the plane
+	}
+	var res []*fauxgl.Triangle
+	for i := 1; i+1 < len(kept); i++ {
+		res = append(res, &fauxgl.Triangle{V1: kept[0], V2: kept[i], V3: kept[i+1]})
+	}
+	return res
+}
+
 func r3mToFauxglVector(normal v3.Vec) fauxgl.Vector {
 	return fauxgl.Vector{X: normal.X, Y: normal.Y, Z: normal.Z}
 }
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"github.com/deadsy/sdfx/vec/v2"
+	"github.com/deadsy/sdfx/vec/v2i"
+	"image/color"
+	"math"
+	"strconv"
+)
+
+// supersampleEdgeThreshold is the minimum difference in any color channel between neighboring pixels of the fast render
+// for them to be refined by the adaptive anti-aliasing (see Opt3Supersample)
+const supersampleEdgeThreshold = 16
+
+// supersampleLevels are the anti-aliasing qualities that can be cycled through from the UI (samples per side of each pixel)
+var supersampleLevels = []int{1, 2, 4}
+
+// nextSupersample returns the next anti-aliasing quality after the given one
+func nextSupersample(supersample int) int {
+	for _, level := range supersampleLevels {
+		if level > max(supersample, 1) { // 0 also means disabled
+			return level
+		}
+	}
+	return supersampleLevels[0]
+}
+
+// supersampleName describes the anti-aliasing quality for the UI
+func supersampleName(supersample int) string {
+	if supersample <= 1 {
+		return "off"
+	}
+	return strconv.Itoa(supersample) + "x" + strconv.Itoa(supersample)
+}
+
+// supersamplePixel averages samples x samples rays, each one jittered inside its cell of a grid centered on the pixel.
+// The depth of the pixel (if needed) is the one of the nearest sample, as each sample overwrites it.
+func (r *renderer3) supersamplePixel(pixel v2i.Vec, pixel01 v2.Vec, job *pixelRender, samples int) color.RGBA {
+	pixelSize := v2.Vec{X: 1 / float64(job.bounds.X), Y: 1 / float64(job.bounds.Y)}
+	depthBufferIndex := -1
+	if len(r.depthBuffer) > 0 {
+		depthBufferIndex = pixel.Y*job.bounds.X + pixel.X
+	}
+	depth := math.MaxFloat64
+	var sum [4]float64
+	for i := 0; i < samples; i++ {
+		for j := 0; j < samples; j++ {
+			jitter := supersampleJitter(pixel, i*samples+j)
+			offset := v2.Vec{X: (float64(i)+jitter.X)/float64(samples) - 0.5, Y: (float64(j)+jitter.Y)/float64(samples) - 0.5}
+			c := r.samplePixel(pixel, pixel01.Add(offset.Mul(pixelSize)), job)
+			sum[0] += float64(c.R)
+			sum[1] += float64(c.G)
+			sum[2] += float64(c.B)
+			sum[3] += float64(c.A)
+			if depthBufferIndex >= 0 {
+				depth = math.Min(depth, r.depthBuffer[depthBufferIndex])
+			}
+		}
+	}
+	if depthBufferIndex >= 0 {
+		r.depthBuffer[depthBufferIndex] = depth
+	}
+	n := float64(samples * samples)
+	return color.RGBA{R: uint8(math.Round(sum[0] / n)), G: uint8(math.Round(sum[1] / n)),
+		B: uint8(math.Round(sum[2] / n)), A: uint8(math.Round(sum[3] / n))}
+}
+
+// supersampleJitter returns a pseudo-random offset in [0, 1) for the given sample of a pixel. It is deterministic, so
+// that rendering the same view twice gives the same image.
+func supersampleJitter(pixel v2i.Vec, sample int) v2.Vec {
+	h := uint32(pixel.X)*0x8da6b343 ^ uint32(pixel.Y)*0xd8163841 ^ uint32(sample)*0xcb1ab31f
+	h ^= h >> 16
+	h *= 0x7feb352d
+	h ^= h >> 15
+	h *= 0x846ca68b
+	h ^= h >> 16
+	return v2.Vec{X: float64(h&0xffff) / 0x10000, Y: float64(h>>16) / 0x10000}
+}
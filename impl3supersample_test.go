@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"github.com/deadsy/sdfx/sdf"
+	"github.com/deadsy/sdfx/vec/v2"
+	"github.com/deadsy/sdfx/vec/v2i"
+	v3 "github.com/deadsy/sdfx/vec/v3"
+	"math"
+	"testing"
+)
+
+func Test_nextSupersample(t *testing.T) {
+	supersample := 0
+	var got []string
+	for i := 0; i < 4; i++ {
+		supersample = nextSupersample(supersample)
+		got = append(got, supersampleName(supersample))
+	}
+	expected := []string{"2x2", "4x4", "off", "2x2"}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+	}
+}
+
+func Test_supersampleJitter(t *testing.T) {
+	seen := map[float64]bool{}
+	for sample := 0; sample < 16; sample++ {
+		jitter := supersampleJitter(v2i.Vec{X: 3, Y: 7}, sample)
+		if jitter.X < 0 || jitter.X >= 1 || jitter.Y < 0 || jitter.Y >= 1 {
+			t.Fatalf("jitter %v out of the sample's cell", jitter)
+		}
+		if jitter != supersampleJitter(v2i.Vec{X: 3, Y: 7}, sample) {
+			t.Fatalf("jitter must be deterministic")
+		}
+		seen[jitter.X] = true
+	}
+	if len(seen) < 8 {
+		t.Fatalf("jitter is not random enough: %v", seen)
+	}
+}
+
+func Test_supersamplePixelDepth(t *testing.T) {
+	s, _ := sdf.Sphere3D(0.5)
+	r := newDevRenderer3(s).(*renderer3)
+	r.depthBuffer = make([]float64, 8*8)
+	job := &pixelRender{bounds: v2i.Vec{X: 8, Y: 8}, camPos: v3.Vec{Y: -10}, camDir: v3.Vec{Y: 1},
+		camViewMatrix: sdf.Identity3d(), camOrthoHalf: 1, maxRay: 20, s: s}
+	// This pixel covers the silhouette of the sphere, so some samples hit it and some miss it
+	pixel := v2i.Vec{X: 6, Y: 4}
+	r.supersamplePixel(pixel, v2.Vec{X: 0.75, Y: 0.5}, job, 4)
+	if depth := r.depthBuffer[pixel.Y*8+pixel.X]; depth == math.MaxFloat64 {
+		t.Fatalf("expected the depth of the nearest sample that hit the surface")
+	}
+}
@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"github.com/deadsy/sdfx/sdf"
+	"github.com/deadsy/sdfx/vec/v3"
+	"log"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Opt3VoxelCache enables a cache of distance samples of the SDF3 (a grid of the given resolution along the longest axis
+// of the bounding box, e.g. 64-128), built in the background on the first render. Once ready, the raycaster uses it to
+// skip the evaluation of the real SDF far from the surface, which makes camera moves much faster for expensive SDFs.
+// The real SDF is still evaluated near the surface, so the quality does not change (assuming a correct distance
+// function), and the raymarching steps color mode only counts the real evaluations.
+// The cache is discarded on recompilation, as it belongs to the running SDF.
+func Opt3VoxelCache(resolution int) Option {
+	return func(r *Renderer) {
+		if r3, ok := r.impl.(*renderer3); ok {
+			r3.voxelResolution = resolution
+		}
+	}
+}
+
+// voxelCache3 is a regular grid of distance samples of a SDF3 over its bounding box. For distance functions (with a
+// maximum slope of 1), each sample gives a lower bound of the distance to the surface for all points around it.
+type voxelCache3 struct {
+	bb       sdf.Box3  // The box covered by the samples (the first sample is at bb.Min)
+	cellSize float64   // The distance between samples
+	counts   [3]int    // The number of samples along each axis
+	samples  []float32 // The distance samples (X changes fastest, then Y, then Z)
+	minSkip  float64   // Only skip evaluations if the lower bound of the distance is larger than this
+}
+
+// newVoxelCache3 samples the SDF (in parallel), with the given number of samples along the longest axis
+func newVoxelCache3(s sdf.SDF3, resolution int) *voxelCache3 {
+	bb := s.BoundingBox()
+	size := bb.Size()
+	c := &voxelCache3{cellSize: math.Max(size.X, math.Max(size.Y, size.Z)) / float64(max(resolution-1, 1))}
+	for axis, axisSize := range []float64{size.X, size.Y, size.Z} {
+		c.counts[axis] = int(math.Ceil(axisSize/c.cellSize)) + 1
+	}
+	c.bb = sdf.Box3{Min: bb.Min, Max: bb.Min.Add(v3.Vec{X: float64(c.counts[0] - 1), Y: float64(c.counts[1] - 1),
+		Z: float64(c.counts[2] - 1)}.MulScalar(c.cellSize))}
+	c.minSkip = 2 * c.cellSize * math.Sqrt(3)
+	c.samples = make([]float32, c.counts[0]*c.counts[1]*c.counts[2])
+	// Each worker samples whole Z slices
+	slices := make(chan int)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for z := range slices {
+				for y := 0; y < c.counts[1]; y++ {
+					for x := 0; x < c.counts[0]; x++ {
+						pos := c.bb.Min.Add(v3.Vec{X: float64(x), Y: float64(y), Z: float64(z)}.MulScalar(c.cellSize))
+						c.samples[c.index(x, y, z)] = float32(s.Evaluate(pos))
+					}
+				}
+			}
+		}()
+	}
+	for z := 0; z < c.counts[2]; z++ {
+		slices <- z
+	}
+	close(slices)
+	wg.Wait()
+	return c
+}
+
+func (c *voxelCache3) index(x, y, z int) int {
+	return (z*c.counts[1]+y)*c.counts[0] + x
+}
+
+// safeDistance returns a lower bound of the distance from pos to the surface (outside of it), or 0 if unknown
+func (c *voxelCache3) safeDistance(pos v3.Vec) float64 {
+	// The surface is contained in the bounding box, so the distance to the box is also a lower bound
+	outside := v3.Vec{X: math.Max(0, math.Max(c.bb.Min.X-pos.X, pos.X-c.bb.Max.X)),
+		Y: math.Max(0, math.Max(c.bb.Min.Y-pos.Y, pos.Y-c.bb.Max.Y)),
+		Z: math.Max(0, math.Max(c.bb.Min.Z-pos.Z, pos.Z-c.bb.Max.Z))}
+	res := outside.Length()
+	// Nearest sample
+	rel := pos.Sub(c.bb.Min).DivScalar(c.cellSize)
+	x := min(max(int(math.Round(rel.X)), 0), c.counts[0]-1)
+	y := min(max(int(math.Round(rel.Y)), 0), c.counts[1]-1)
+	z := min(max(int(math.Round(rel.Z)), 0), c.counts[2]-1)
+	sample := float64(c.samples[c.index(x, y, z)])
+	samplePos := c.bb.Min.Add(v3.Vec{X: float64(x), Y: float64(y), Z: float64(z)}.MulScalar(c.cellSize))
+	// Negative (inside) samples give no bound, which is also right for clipped surfaces (see Opt3Clip): they are only
+	// cut where the original surface is inside
+	return math.Max(res, sample-pos.Sub(samplePos).Length())
+}
+
+// raycast is sdf.Raycast3, but skipping the evaluation of s when the cache knows that the surface is far away.
+// The returned steps only count the evaluations of s.
+func (c *voxelCache3) raycast(s sdf.SDF3, from, dir v3.Vec, scaleAndSigmoid, stepScale, epsilon, maxDist float64,
+	maxSteps int) (collision v3.Vec, t float64, steps int) {
+	dirN := dir.Normalize()
+	pos := from
+	for {
+		val := c.safeDistance(pos)
+		if val <= c.minSkip { // Close to the surface: evaluate the real SDF
+			val = math.Abs(s.Evaluate(pos))
+			if val < epsilon {
+				return pos, t, steps // Success
+			}
+			steps++
+			if steps == maxSteps {
+				return collision, -1, steps // Failure
+			}
+		}
+		if scaleAndSigmoid > 0 {
+			val = sigmoidScaled(val * 10)
+		}
+		delta := val * stepScale
+		t += delta
+		pos = pos.Add(dirN.MulScalar(delta))
+		if t < 0 || t > maxDist {
+			return collision, -1, steps // Failure
+		}
+	}
+}
+
+// sigmoidScaled is the same as the one used by sdf.Raycast3
+func sigmoidScaled(x float64) float64 {
+	return 2/(1+math.Exp(-x)) - 1
+}
+
+// voxelCache returns the cache of the SDF (see Opt3VoxelCache) if it is enabled and ready, starting to build it in the
+// background otherwise
+func (r *renderer3) voxelCache() *voxelCache3 {
+	if r.voxelResolution <= 0 {
+		return nil
+	}
+	r.voxelCacheOnce.Do(func() {
+		s := r.s
+		go func() {
+			defer func() {
+				if rec := recover(); rec != nil { // The cache stays disabled (the render will show the error)
+					log.Println("[DevRenderer] Error building voxel cache:", rec)
+				}
+			}()
+			start := time.Now()
+			cache := newVoxelCache3(s, r.voxelResolution)
+			r.voxelCacheReady.Store(cache)
+			log.Println("[DevRenderer] Voxel cache of", len(cache.samples), "samples built in", time.Since(start))
+		}()
+	})
+	return r.voxelCacheReady.Load()
+}
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"github.com/deadsy/sdfx/sdf"
+	"github.com/deadsy/sdfx/vec/v3"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func Test_voxelCache3_safeDistance(t *testing.T) {
+	s, _ := sdf.Sphere3D(1)
+	cache := newVoxelCache3(s, 32)
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		pos := v3.Vec{X: rnd.Float64()*6 - 3, Y: rnd.Float64()*6 - 3, Z: rnd.Float64()*6 - 3}
+		if safe, dist := cache.safeDistance(pos), math.Abs(s.Evaluate(pos)); safe > dist+1e-6 {
+			t.Fatalf("%v: the safe distance %f is larger than the real distance %f", pos, safe, dist)
+		}
+	}
+}
+
+func Test_voxelCache3_raycast(t *testing.T) {
+	box, _ := sdf.Box3D(v3.Vec{X: 2, Y: 1, Z: 1}, 0.2)
+	sphere, _ := sdf.Sphere3D(0.6)
+	s := sdf.Union3D(box, sdf.Transform3D(sphere, sdf.Translate3d(v3.Vec{X: 1, Y: -0.4})))
+	cache := newVoxelCache3(s, 32)
+	const eps, maxDist, maxSteps = 1e-3, 20, 200
+	from := v3.Vec{X: 1, Y: -5, Z: 2}
+	totalSteps, totalCachedSteps := 0, 0
+	for x := -1.5; x <= 1.5; x += 0.1 {
+		for z := -1.; z <= 1; z += 0.1 {
+			dir := v3.Vec{X: x, Y: 0, Z: z}.Sub(from)
+			hit, dist, steps := sdf.Raycast3(s, from, dir, 0, 1, eps, maxDist, maxSteps)
+			cachedHit, cachedDist, cachedSteps := cache.raycast(s, from, dir, 0, 1, eps, maxDist, maxSteps)
+			if steps == maxSteps { // The cache may reach further, as it only counts the evaluations of the SDF
+				continue
+			}
+			if (dist < 0) != (cachedDist < 0) || dist >= 0 && hit.Sub(cachedHit).Length() > 10*eps {
+				t.Fatalf("ray to (%f, 0, %f): expected hit %v (%f), got %v (%f)", x, z, hit, dist, cachedHit, cachedDist)
+			}
+			totalSteps += steps
+			totalCachedSteps += cachedSteps
+		}
+	}
+	if totalCachedSteps >= totalSteps {
+		t.Fatalf("the cache should reduce the evaluations of the SDF: %d >= %d", totalCachedSteps, totalSteps)
+	}
+}
@@ -1,30 +1,30 @@
 package ui
 
 import (
+	"context"
+	"fmt"
 	"github.com/Yeicor/sdfx-ui/internal"
 	"github.com/deadsy/sdfx/vec/v2"
 	"github.com/deadsy/sdfx/vec/v2i"
+	"image"
 	"image/color"
 	"math/rand"
 	"runtime"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
 )
 
-type jobInternal struct {
-	pixel   v2i.Vec
-	pixel01 v2.Vec
-	data    interface{}
-}
-
-type jobResult struct {
-	pixel v2i.Vec
-	color color.RGBA
-}
+// pixelRenderer computes the color of a pixel, given its position in pixels and relative to the image size (from 0 to 1).
+// It is called concurrently from multiple goroutines.
+type pixelRenderer func(pixel v2i.Vec, pixel01 v2.Vec) color.RGBA
 
-func implCommonRender(genJob func(pixel v2i.Vec, pixel01 v2.Vec) interface{},
-	processJob func(pixel v2i.Vec, pixel01 v2.Vec, job interface{}) *jobResult,
-	args *internal.RenderArgs, pixelsRand *[]int) error {
+// renderTileSize is the number of pixels that each worker renders before writing them to the image (and checking for
+// cancellation and sending a partial render). Tiles are chunks of the list of pixels to render, so they are scattered
+// over the image when rendering in random or progressive order.
+const renderTileSize = 1024
 
+func implCommonRender(renderPixel pixelRenderer, args *internal.RenderArgs, pixelsRand *[]int) error {
 	// Set all pixels to transparent initially (for partial renderings to work)
 	args.CachedRenderLock.Lock()
 	for i := 3; i < len(args.FullRender.Pix); i += 4 {
@@ -40,81 +40,181 @@ func implCommonRender(genJob func(pixel v2i.Vec, pixel01 v2.Vec) interface{},
 		// Random seed shouldn't matter, just make pixel coloring seem random for partial renders
 		*pixelsRand = rand.Perm(pixelCount)
 	}
+	args.Stats.Start(pixelCount)
+
+	// Progressive renders show a complete low resolution preview first, refining it in each pass
+	args.StateLock.RLock()
+	progressive := args.State.Progressive
+	args.StateLock.RUnlock()
+	pixels := *pixelsRand
+	if progressive {
+		pixels = progressiveOrder(pixels, boundsSize.X)
+	}
+
+	err := implCommonRenderPixels(renderPixel, args, pixels, progressive)
+	if args.PartialRenders != nil {
+		close(args.PartialRenders)
+	}
+	return err
+}
 
-	// Spawn the workers that will render 1 pixel at a time
-	jobs := make(chan *jobInternal)
-	jobResults := make(chan *jobResult)
+// implCommonRenderPixels renders only the given pixels (indices of args.FullRender, in that order) in parallel, sending
+// partial renders (without closing the channel) and counting them as done.
+// If progressive, each rendered pixel also fills the block of pixels not rendered yet (see progressiveFill).
+func implCommonRenderPixels(renderPixel pixelRenderer, args *internal.RenderArgs, pixels []int, progressive bool) error {
+	bounds := args.FullRender.Bounds()
+	boundsSize := v2i.Vec{bounds.Size().X, bounds.Size().Y}
+	var done []bool // The rendered pixels (only needed for progressive renders)
+	if progressive {
+		done = make([]bool, boundsSize.X*boundsSize.Y)
+	}
+
+	// The whole render is stopped on the first panic (e.g. a bug in the SDF), which is returned as an error
+	ctx, cancel := context.WithCancel(args.Ctx)
+	defer cancel()
+	var panicErr error
+	panicErrLock := &sync.Mutex{}
+
+	// Each worker takes the next tile until there are none left, rendering it into its own buffer and then writing it
+	// to the image (the lock is only held while writing)
+	tileCount := (len(pixels) + renderTileSize - 1) / renderTileSize
+	var nextTile atomic.Int64
 	workerWg := &sync.WaitGroup{}
 	for i := 0; i < runtime.NumCPU(); i++ {
 		workerWg.Add(1)
 		go func() {
 			defer workerWg.Done()
-		loop:
-			for {
-				job, ok := <-jobs
-				if !ok { // Cancelled or finished render (stopped generating jobs)
-					break loop
+			defer func() {
+				if rec := recover(); rec != nil {
+					panicErrLock.Lock()
+					if panicErr == nil {
+						panicErr = fmt.Errorf("panic while rendering: %v\n%s", rec, debug.Stack())
+					}
+					panicErrLock.Unlock()
+					cancel()
+				}
+			}()
+			colors := make([]color.RGBA, renderTileSize)
+			for ctx.Err() == nil {
+				tile := int(nextTile.Add(1) - 1)
+				if tile >= tileCount {
+					break
+				}
+				tilePixels := pixels[tile*renderTileSize : min((tile+1)*renderTileSize, len(pixels))]
+				for j, pixelIndex := range tilePixels {
+					pixel := v2i.Vec{X: pixelIndex % boundsSize.X, Y: pixelIndex / boundsSize.X}
+					pixel01 := v2.Vec{X: float64(pixel.X) / float64(boundsSize.X), Y: float64(pixel.Y) / float64(boundsSize.Y)}
+					colors[j] = renderPixel(pixel, pixel01)
+				}
+				if ctx.Err() != nil { // Do not modify the image after the render is cancelled
+					break
+				}
+				args.CachedRenderLock.Lock()
+				for j, pixelIndex := range tilePixels {
+					pixel := v2i.Vec{X: pixelIndex % boundsSize.X, Y: pixelIndex / boundsSize.X}
+					if progressive {
+						progressiveFill(args.FullRender, done, pixel, colors[j])
+					} else {
+						args.FullRender.SetRGBA(pixel.X, pixel.Y, colors[j])
+					}
+				}
+				args.Stats.AddPixelsDone(len(tilePixels))
+				args.CachedRenderLock.Unlock()
+				if args.PartialRenders != nil { // Send the partial render update
+					sendPartialRender(ctx, args.PartialRenders, args.FullRender)
 				}
-				jobResults <- processJob(job.pixel, job.pixel01, job.data)
 			}
 		}()
 	}
-	go func() { // Make sure job results are closed after all jobs are processed
-		workerWg.Wait()
-		close(jobResults)
-	}()
+	workerWg.Wait()
 
-	// Spawn the work generator
-	go func() {
-	loop: // Sample each pixel on the image separately (and in random order to see the image faster)
-		for _, randPixelIndex := range *pixelsRand {
-			// Sample a random pixel in the image
-			sampledPixel := v2i.Vec{X: randPixelIndex % boundsSize.X, Y: randPixelIndex / boundsSize.X}
-			sampledPixel01 := v2.Vec{X: float64(sampledPixel.X) / float64(boundsSize.X), Y: float64(sampledPixel.Y) / float64(boundsSize.Y)}
-			// Queue the job for parallel processing
-			select {
-			case <-args.Ctx.Done():
-				break loop
-			case jobs <- &jobInternal{
-				pixel:   sampledPixel,
-				pixel01: sampledPixel01,
-				data:    genJob(sampledPixel, sampledPixel01),
-			}:
+	panicErrLock.Lock()
+	defer panicErrLock.Unlock()
+	if panicErr != nil {
+		return panicErr
+	}
+	return args.Ctx.Err()
+}
+
+// sendPartialRender sends a partial render update, giving up if the render is cancelled (as the consumer may have
+// stopped reading)
+func sendPartialRender(ctx context.Context, partialRenders chan<- *image.RGBA, img *image.RGBA) {
+	select {
+	case partialRenders <- img:
+	case <-ctx.Done():
+	}
+}
+
+// implCommonRenderRefined is implCommonRender followed by a refinement pass, which renders again (using refinePixel) the
+// pixels selected by refinePixels given the first render. The first render is shown as soon as it is ready.
+func implCommonRenderRefined(renderPixel, refinePixel pixelRenderer, args *internal.RenderArgs, pixelsRand *[]int,
+	refinePixels func(img *image.RGBA) []int) error {
+	// The first render must not close the partial renders channel, as the refinement pass will keep using it
+	partialRenders := args.PartialRenders
+	firstArgs := *args
+	firstDone := make(chan struct{})
+	if partialRenders != nil {
+		firstPartialRenders := make(chan *image.RGBA)
+		firstArgs.PartialRenders = firstPartialRenders
+		go func() {
+			for partialRender := range firstPartialRenders { // Keep draining after a cancellation
+				sendPartialRender(args.Ctx, partialRenders, partialRender)
 			}
+			close(firstDone)
+		}()
+	} else {
+		close(firstDone)
+	}
+	err := implCommonRender(renderPixel, &firstArgs, pixelsRand)
+	<-firstDone
+	if err == nil {
+		if partialRenders != nil { // Show the fast render while refining it
+			sendPartialRender(args.Ctx, partialRenders, args.FullRender)
 		}
-		close(jobs) // Close the jobs channel to mark the end
-	}()
+		args.CachedRenderLock.RLock()
+		pixels := refinePixels(args.FullRender)
+		args.CachedRenderLock.RUnlock()
+		args.Stats.AddPixels(len(pixels))
+		err = implCommonRenderPixels(refinePixel, args, pixels, false)
+	}
+	if partialRenders != nil {
+		close(partialRenders)
+	}
+	return err
+}
 
-	// Listen for all job results and update the image, freeing locks and sending a partial image update every batch of pixels
-	const pixelBatch = 1000 // Configurable? Shouldn't matter much as you can already configure time between partial renders.
-	pixelNum := 0
-	args.CachedRenderLock.Lock()
-	var err error
-pixelLoop:
-	for renderedPixel := range jobResults {
-		args.FullRender.SetRGBA(renderedPixel.pixel.X, renderedPixel.pixel.Y, renderedPixel.color)
-		pixelNum++
-		if pixelNum%pixelBatch == 0 {
-			args.CachedRenderLock.Unlock()
-			runtime.Gosched() // Breathe (let renderer do something, best-effort)
-			select {          // Check if this render is cancelled (could also check every pixel...)
-			case <-args.Ctx.Done():
-				err = args.Ctx.Err()
-				break pixelLoop
-			default:
-			}
-			if args.PartialRenders != nil { // Send the partial render update
-				// Use a shader to fill transparent pixel with nearest neighbors to make it look better while rendering (losing previous background render)?
-				args.PartialRenders <- args.FullRender
+// edgePixels returns the pixels (indices of img, in random order) whose color differs from any of its neighbors by more
+// than threshold in any channel, which are the ones that benefit from anti-aliasing (silhouettes, sharp edges...)
+func edgePixels(img *image.RGBA, threshold uint8) []int {
+	size := img.Rect.Size()
+	differs := func(a, b int) bool {
+		for c := 0; c < 4; c++ {
+			va, vb := img.Pix[a*4+c], img.Pix[b*4+c]
+			if va > vb && va-vb > threshold || vb > va && vb-va > threshold {
+				return true
 			}
-			args.CachedRenderLock.Lock()
 		}
+		return false
 	}
-	if err == nil {
-		args.CachedRenderLock.Unlock()
+	isEdge := make([]bool, size.X*size.Y)
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			i := y*size.X + x
+			if x+1 < size.X && differs(i, i+1) {
+				isEdge[i], isEdge[i+1] = true, true
+			}
+			if y+1 < size.Y && differs(i, i+size.X) {
+				isEdge[i], isEdge[i+size.X] = true, true
+			}
+		}
 	}
-	if args.PartialRenders != nil {
-		close(args.PartialRenders)
+	var res []int
+	for i, edge := range isEdge {
+		if edge {
+			res = append(res, i)
+		}
 	}
-	return err
+	// Random order, to see the refinement of the whole image faster (like the first render)
+	rand.Shuffle(len(res), func(i, j int) { res[i], res[j] = res[j], res[i] })
+	return res
 }
@@ -0,0 +1,203 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Yeicor/sdfx-ui/internal"
+	"github.com/deadsy/sdfx/vec/v2"
+	"github.com/deadsy/sdfx/vec/v2i"
+	"image"
+	"image/color"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// BenchmarkImplCommonRender measures the overhead of scheduling the pixels, using a very cheap pixel renderer
+func BenchmarkImplCommonRender(b *testing.B) {
+	for _, progressive := range []bool{false, true} {
+		b.Run(fmt.Sprintf("progressive=%t", progressive), func(b *testing.B) {
+			var pixelsRand []int
+			args := &internal.RenderArgs{
+				Ctx:              context.Background(),
+				State:            &internal.RendererState{Progressive: progressive},
+				StateLock:        &sync.RWMutex{},
+				CachedRenderLock: &sync.RWMutex{},
+				FullRender:       image.NewRGBA(image.Rect(0, 0, 1920/4, 1080/4)),
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				err := implCommonRender(func(pixel v2i.Vec, pixel01 v2.Vec) color.RGBA {
+					if pixel01.Sub(v2.Vec{X: 0.5, Y: 0.5}).Length() < 0.25 { // A circle
+						return color.RGBA{R: 255, A: 255}
+					}
+					return color.RGBA{A: 255}
+				}, args, &pixelsRand)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func Test_implCommonRenderCancel(t *testing.T) {
+	var pixelsRand []int
+	ctx, cancel := context.WithCancel(context.Background())
+	rendered := atomic.Int64{}
+	err := implCommonRender(func(pixel v2i.Vec, pixel01 v2.Vec) color.RGBA {
+		if rendered.Add(1) == 10 {
+			cancel()
+		}
+		return color.RGBA{}
+	}, &internal.RenderArgs{
+		Ctx:              ctx,
+		State:            &internal.RendererState{},
+		StateLock:        &sync.RWMutex{},
+		CachedRenderLock: &sync.RWMutex{},
+		FullRender:       image.NewRGBA(image.Rect(0, 0, 256, 256)),
+	}, &pixelsRand)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the render to be cancelled, got %v", err)
+	}
+	if rendered.Load() >= 256*256 {
+		t.Fatalf("the render should stop early after being cancelled")
+	}
+}
+
+func Test_implCommonRenderCancelWhileSending(t *testing.T) {
+	for _, refined := range []bool{false, true} {
+		var pixelsRand []int
+		ctx, cancel := context.WithCancel(context.Background())
+		partialRenders := make(chan *image.RGBA)
+		go func() { // Like the remote consumer: stop reading and then cancel (while the workers may be sending)
+			<-partialRenders
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+		}()
+		args := &internal.RenderArgs{
+			Ctx:              ctx,
+			State:            &internal.RendererState{},
+			StateLock:        &sync.RWMutex{},
+			CachedRenderLock: &sync.RWMutex{},
+			PartialRenders:   partialRenders,
+			FullRender:       image.NewRGBA(image.Rect(0, 0, 256, 256)),
+		}
+		renderPixel := func(pixel v2i.Vec, pixel01 v2.Vec) color.RGBA {
+			return color.RGBA{}
+		}
+		done := make(chan error)
+		go func() {
+			if refined {
+				done <- implCommonRenderRefined(renderPixel, renderPixel, args, &pixelsRand, func(img *image.RGBA) []int {
+					return nil
+				})
+			} else {
+				done <- implCommonRender(renderPixel, args, &pixelsRand)
+			}
+		}()
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("refined %t: expected the render to be cancelled, got %v", refined, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("refined %t: the render hangs after being cancelled while sending partial renders", refined)
+		}
+	}
+}
+
+func Test_implCommonRenderPanic(t *testing.T) {
+	var pixelsRand []int
+	err := implCommonRender(func(pixel v2i.Vec, pixel01 v2.Vec) color.RGBA {
+		if pixel.X == 5 {
+			panic("bad SDF")
+		}
+		return color.RGBA{}
+	}, &internal.RenderArgs{
+		Ctx:              context.Background(),
+		State:            &internal.RendererState{},
+		StateLock:        &sync.RWMutex{},
+		CachedRenderLock: &sync.RWMutex{},
+		FullRender:       image.NewRGBA(image.Rect(0, 0, 64, 64)),
+	}, &pixelsRand)
+	if err == nil || !strings.Contains(err.Error(), "bad SDF") {
+		t.Fatalf("expected the panic to be returned as an error, got %v", err)
+	}
+}
+
+func Test_implCommonRenderRefined(t *testing.T) {
+	var pixelsRand []int
+	partialRenders := make(chan *image.RGBA)
+	partialRendersCount := 0
+	partialRendersDone := make(chan struct{})
+	go func() {
+		for range partialRenders {
+			partialRendersCount++
+		}
+		close(partialRendersDone)
+	}()
+	stats := &internal.RenderStats{}
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	err := implCommonRenderRefined(func(pixel v2i.Vec, pixel01 v2.Vec) color.RGBA {
+		return color.RGBA{R: 255, A: 255}
+	}, func(pixel v2i.Vec, pixel01 v2.Vec) color.RGBA {
+		return color.RGBA{G: 255, A: 255}
+	}, &internal.RenderArgs{
+		Ctx:              context.Background(),
+		State:            &internal.RendererState{},
+		StateLock:        &sync.RWMutex{},
+		CachedRenderLock: &sync.RWMutex{},
+		PartialRenders:   partialRenders,
+		FullRender:       img,
+		Stats:            stats,
+	}, &pixelsRand, func(img *image.RGBA) []int {
+		if img.RGBAAt(0, 0).R != 255 {
+			t.Errorf("the refinement should start after the first render is complete")
+		}
+		return []int{0, 65} // (0, 0) and (1, 1)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-partialRendersDone // The channel must be closed only once, at the end
+	if partialRendersCount < 3 {
+		t.Errorf("expected partial renders from both passes, got %d", partialRendersCount)
+	}
+	for _, pixel := range []image.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 0}} {
+		refined := pixel.X == pixel.Y
+		if got := img.RGBAAt(pixel.X, pixel.Y); (got.G == 255) != refined {
+			t.Errorf("pixel %v: refined %t, got color %v", pixel, refined, got)
+		}
+	}
+	if snapshot := stats.Snapshot(); snapshot.Pixels != 64*64+2 || snapshot.PixelsDone != snapshot.Pixels {
+		t.Errorf("unexpected stats %#v", snapshot)
+	}
+}
+
+func Test_edgePixels(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			c := color.RGBA{R: 100, A: 255}
+			if x >= 2 { // Vertical edge between columns 1 and 2
+				c.R = 200
+			} else if x == 0 { // Small differences are not edges
+				c.R = 110
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	edges := edgePixels(img, 16)
+	sort.Ints(edges)
+	expected := []int{1, 2, 5, 6, 9, 10}
+	if !reflect.DeepEqual(edges, expected) {
+		t.Fatalf("expected edges %v, got %v", expected, edges)
+	}
+}
@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"github.com/deadsy/sdfx/vec/v2i"
+	"image"
+	"image/color"
+	"math/bits"
+)
+
+// progressiveMaxStride is the distance between the pixels of the first (coarsest) pass of a progressive render
+const progressiveMaxStride = 1 << progressiveMaxStrideLog2
+const progressiveMaxStrideLog2 = 4
+
+// progressiveStride returns the stride of the pass of a progressive render that renders the given pixel: the largest
+// power of two (up to progressiveMaxStride) that divides both coordinates. Each pass only renders the pixels that were
+// not rendered by the coarser ones, so no sample is computed twice.
+func progressiveStride(pixel v2i.Vec) int {
+	return 1 << min(bits.TrailingZeros(uint(pixel.X|pixel.Y)), progressiveMaxStrideLog2)
+}
+
+// progressiveOrder sorts the pixels (indices of an image of the given width) by pass, from coarse to fine, keeping the
+// original (random) order inside each pass
+func progressiveOrder(pixels []int, width int) []int {
+	// Counting sort by pass (stable)
+	passOf := func(pixelIndex int) int {
+		return progressiveMaxStrideLog2 - bits.TrailingZeros(uint(progressiveStride(v2i.Vec{X: pixelIndex % width, Y: pixelIndex / width})))
+	}
+	var passStart [progressiveMaxStrideLog2 + 2]int
+	for _, pixelIndex := range pixels {
+		passStart[passOf(pixelIndex)+1]++
+	}
+	for pass := 1; pass < len(passStart); pass++ {
+		passStart[pass] += passStart[pass-1]
+	}
+	res := make([]int, len(pixels))
+	for _, pixelIndex := range pixels {
+		pass := passOf(pixelIndex)
+		res[passStart[pass]] = pixelIndex
+		passStart[pass]++
+	}
+	return res
+}
+
+// progressiveFill sets the color of a rendered pixel, also using it as a preview (upscaled) for the pixels of its block
+// that are not rendered yet. done keeps track of the rendered pixels of img.
+func progressiveFill(img *image.RGBA, done []bool, pixel v2i.Vec, c color.RGBA) {
+	size := img.Rect.Size()
+	done[pixel.Y*size.X+pixel.X] = true
+	img.SetRGBA(pixel.X, pixel.Y, c)
+	stride := progressiveStride(pixel)
+	for y := pixel.Y; y < pixel.Y+stride && y < size.Y; y++ {
+		for x := pixel.X; x < pixel.X+stride && x < size.X; x++ {
+			if !done[y*size.X+x] { // Finer passes may already be done (the jobs are processed in parallel)
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"context"
+	"github.com/Yeicor/sdfx-ui/internal"
+	"github.com/deadsy/sdfx/vec/v2"
+	"github.com/deadsy/sdfx/vec/v2i"
+	"image"
+	"image/color"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func Test_progressiveStride(t *testing.T) {
+	for pixel, expected := range map[v2i.Vec]int{{X: 0, Y: 0}: 16, {X: 32, Y: 48}: 16, {X: 8, Y: 16}: 8,
+		{X: 4, Y: 12}: 4, {X: 2, Y: 0}: 2, {X: 1, Y: 0}: 1, {X: 16, Y: 3}: 1} {
+		if got := progressiveStride(pixel); got != expected {
+			t.Errorf("pixel %v: expected stride %d, got %d", pixel, expected, got)
+		}
+	}
+}
+
+func Test_progressiveOrder(t *testing.T) {
+	const width = 20
+	pixels := []int{1, 2*width + 2, 0, 3, 16, 2, width}
+	got := progressiveOrder(pixels, width)
+	expected := []int{0, 16, 2*width + 2, 2, 1, 3, width}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
+
+func Test_progressiveFill(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	done := make([]bool, 20*20)
+	red, green := color.RGBA{R: 255, A: 255}, color.RGBA{G: 255, A: 255}
+	progressiveFill(img, done, v2i.Vec{X: 1, Y: 1}, green) // Finer pass completed before the coarse one
+	progressiveFill(img, done, v2i.Vec{X: 0, Y: 0}, red)
+	if img.RGBAAt(1, 1) != green || img.RGBAAt(0, 1) != red || img.RGBAAt(15, 15) != red || img.RGBAAt(16, 0) != (color.RGBA{}) {
+		t.Fatalf("unexpected fill")
+	}
+	progressiveFill(img, done, v2i.Vec{X: 16, Y: 16}, green) // Clipped to the image
+	if img.RGBAAt(19, 19) != green {
+		t.Fatalf("unexpected fill at the border")
+	}
+}
+
+func Test_implCommonRenderProgressive(t *testing.T) {
+	render := func(progressive bool) *image.RGBA {
+		var pixelsRand []int
+		img := image.NewRGBA(image.Rect(0, 0, 67, 45))
+		err := implCommonRender(func(pixel v2i.Vec, pixel01 v2.Vec) color.RGBA {
+			return color.RGBA{R: uint8(pixel.X), G: uint8(pixel.Y), A: 255}
+		}, &internal.RenderArgs{
+			Ctx:              context.Background(),
+			State:            &internal.RendererState{Progressive: progressive},
+			StateLock:        &sync.RWMutex{},
+			CachedRenderLock: &sync.RWMutex{},
+			FullRender:       img,
+		}, &pixelsRand)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return img
+	}
+	if !reflect.DeepEqual(render(true).Pix, render(false).Pix) {
+		t.Fatalf("progressive rendering should not change the final image")
+	}
+}
@@ -1,12 +1,16 @@
 package ui
 
 import (
+	"errors"
+	"fmt"
 	"github.com/Yeicor/sdfx-ui/internal"
 	"github.com/barkimedes/go-deepcopy"
 	"github.com/deadsy/sdfx/sdf"
 	"github.com/deadsy/sdfx/vec/v2i"
 	"log"
 	"net/rpc"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,11 +18,40 @@ import (
 type rendererClient struct {
 	cl                *rpc.Client
 	cachedReflectTree *internal.ReflectTree // Avoids sending the whole metadata tree over the network more than once
+	frameEncoding     string                // The negotiated encoding for rendered images ("" if not supported by the child)
+	closed            chan struct{}         // Closed when the child is being stopped or the connection is closed
+	closeOnce         *sync.Once
+	rendered          atomic.Bool // Whether any render completed (see Renderer.watchChild)
 }
 
-// newDevRendererClient see rendererClient
-func newDevRendererClient(client *rpc.Client) internal.DevRendererImpl {
-	return &rendererClient{cl: client}
+// newDevRendererClient see rendererClient. It checks that the child is compatible (closing the client otherwise).
+func newDevRendererClient(client *rpc.Client, compressFrames bool) (internal.DevRendererImpl, error) {
+	d := &rendererClient{cl: client, closed: make(chan struct{}), closeOnce: &sync.Once{}}
+	var hello internal.Hello
+	err := d.cl.Call("RendererService.Hello", internal.NewHello(), &hello)
+	if err == nil {
+		err = hello.CheckCompatible()
+	} else {
+		err = fmt.Errorf("handshake failed, the new code may use a different version of sdfx-ui: %w", err)
+	}
+	if err != nil {
+		if err2 := d.cl.Close(); err2 != nil {
+			log.Println("[DevRenderer] Error closing the connection to the new code:", err2)
+		}
+		return nil, err
+	}
+	// Negotiate how to send rendered images: prefer only sending the pixels that changed, compressed if requested
+	wanted := []string{internal.FrameEncodingDelta}
+	if compressFrames {
+		wanted = []string{internal.FrameEncodingDeltaDeflate, internal.FrameEncodingDelta}
+	}
+	for _, encoding := range wanted {
+		if hello.HasCapability(internal.CapabilityFrameEncodingPrefix + encoding) {
+			d.frameEncoding = encoding
+			break
+		}
+	}
+	return d, nil
 }
 
 func (d *rendererClient) Dimensions() int {
@@ -65,8 +98,10 @@ func (d *rendererClient) Render(args *internal.RenderArgs) error {
 	fullRenderSize := args.FullRender.Bounds().Size()
 	args.StateLock.RLock() // Clone the state to avoid locking while the rendering is happening
 	argsRemote := &internal.RemoteRenderArgs{
-		RenderSize: v2i.Vec{X: fullRenderSize.X, Y: fullRenderSize.Y},
-		State:      deepcopy.MustAnything(args.State).(*internal.RendererState),
+		RenderSize:    v2i.Vec{X: fullRenderSize.X, Y: fullRenderSize.Y},
+		State:         deepcopy.MustAnything(args.State).(*internal.RendererState),
+		FrameEncoding: d.frameEncoding,
+		Headless:      args.Headless,
 	}
 	argsRemote.State.ReflectTree = nil // HACK: Avoids sending the whole metadata tree over the network more than once
 	args.StateLock.RUnlock()
@@ -75,12 +110,19 @@ func (d *rendererClient) Render(args *internal.RenderArgs) error {
 	if err != nil {
 		return err
 	}
+	frameDecoder := &internal.FrameDecoder{} // Frames are relative to the previous one of the same render
 	for {
 		var res internal.RemoteRenderResults
 		err = d.cl.Call("RendererService.RenderGet", ignoreMe, &res)
 		if err != nil {
 			return err
 		}
+		if res.Frame != nil {
+			res.RenderedImg, err = frameDecoder.Decode(res.Frame)
+			if err != nil {
+				return err
+			}
+		}
 		select {
 		case <-args.Ctx.Done(): // Cancel remote renderer also
 			err = d.cl.Call("RendererService.RenderCancel", ignoreMe, &ignoreMe)
@@ -90,6 +132,7 @@ func (d *rendererClient) Render(args *internal.RenderArgs) error {
 			return args.Ctx.Err()
 		default:
 		}
+		args.Stats.Set(res.Stats)
 		if res.NewState != nil {
 			args.StateLock.Lock() // Clone back the new state to avoid locking while the rendering is happening
 			*args.State = *res.NewState
@@ -106,6 +149,7 @@ func (d *rendererClient) Render(args *internal.RenderArgs) error {
 			args.CachedRenderLock.Lock()
 			*args.FullRender = *res.RenderedImg
 			args.CachedRenderLock.Unlock()
+			d.rendered.Store(true)
 			break
 		}
 	}
@@ -114,5 +158,36 @@ func (d *rendererClient) Render(args *internal.RenderArgs) error {
 
 func (d *rendererClient) Shutdown(timeout time.Duration) error {
 	var out int
-	return d.cl.Call("RendererService.Shutdown", &timeout, &out)
+	return d.callTimeout("RendererService.Shutdown", &timeout, &out, 2*timeout) // Also works if the child is frozen
+}
+
+// Ping checks that the child is still responsive, failing if it does not answer in time.
+func (d *rendererClient) Ping(timeout time.Duration) (*internal.PingResults, error) {
+	var out internal.PingResults
+	err := d.callTimeout("RendererService.Ping", 0, &out, timeout)
+	return &out, err
+}
+
+// callTimeout is like rpc.Client.Call, but gives up waiting for the reply after the timeout.
+func (d *rendererClient) callTimeout(serviceMethod string, args interface{}, reply interface{}, timeout time.Duration) error {
+	call := d.cl.Go(serviceMethod, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+		return call.Error
+	case <-time.After(timeout):
+		return errors.New(serviceMethod + " timeout after " + timeout.String())
+	}
+}
+
+// Stop marks the child as stopped (before shutting it down, see Shutdown) so that it is no longer watched for crashes.
+func (d *rendererClient) Stop() {
+	d.closeOnce.Do(func() {
+		close(d.closed)
+	})
+}
+
+// Close stops the child (see Stop) and closes the connection, aborting all calls in progress.
+func (d *rendererClient) Close() error {
+	d.Stop()
+	return d.cl.Close()
 }
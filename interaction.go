@@ -15,6 +15,7 @@ import (
 	"image/color"
 	"math"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -63,6 +64,12 @@ func (r *Renderer) onUpdateInputsCommon() {
 		r.implStateLock.Unlock()
 		r.rerender()
 	}
+	// Statistics (no need to render again)
+	if inpututil.IsKeyJustPressed(ebiten.KeyI) {
+		r.implStateLock.Lock()
+		r.showStats = !r.showStats
+		r.implStateLock.Unlock()
+	}
 	// Color
 	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
 		r.implStateLock.Lock()
@@ -70,6 +77,24 @@ func (r *Renderer) onUpdateInputsCommon() {
 		r.implStateLock.Unlock()
 		r.rerender()
 	}
+	// Bookmarks
+	for slot, key := range bookmarkKeys {
+		if inpututil.IsKeyJustPressed(key) {
+			r.implStateLock.Lock()
+			if ebiten.IsKeyPressed(ebiten.KeyControl) {
+				bookmark := r.saveBookmark(slot)
+				r.showNotice("Saved " + bookmark.Name + " [" + strconv.Itoa(slot+1) + "]")
+				r.implStateLock.Unlock()
+			} else if bookmark := r.recallBookmark(slot); bookmark != nil {
+				r.showNotice("Recalled " + bookmark.Name + " [" + strconv.Itoa(slot+1) + "]")
+				r.implStateLock.Unlock()
+				r.rerender()
+			} else {
+				r.showNotice("No bookmark saved at [" + strconv.Itoa(slot+1) + "], use [Ctrl+" + strconv.Itoa(slot+1) + "] to save one")
+				r.implStateLock.Unlock()
+			}
+		}
+	}
 	if r.smoothCamera {
 		r.implStateLock.RLock()
 		if r.translateFrom.X != math.MaxInt {
@@ -79,6 +104,16 @@ func (r *Renderer) onUpdateInputsCommon() {
 	}
 }
 
+// bookmarkKeys are the keys for each bookmark slot
+var bookmarkKeys = [maxBookmarks]ebiten.Key{ebiten.Key1, ebiten.Key2, ebiten.Key3, ebiten.Key4, ebiten.Key5,
+	ebiten.Key6, ebiten.Key7, ebiten.Key8, ebiten.Key9}
+
+// showNotice shows a short message on screen for a few seconds (must be called with the implStateLock held)
+func (r *Renderer) showNotice(msg string) {
+	r.notice = msg
+	r.noticeUntil = time.Now().Add(3 * time.Second)
+}
+
 func (r *Renderer) onUpdateInputsSDF2() {
 	// Zooming
 	_, wheelUpDown := ebiten.Wheel()
@@ -133,9 +168,11 @@ func (r *Renderer) onUpdateInputsSDF2() {
 
 func (r *Renderer) apply2DCameraMoveTo(cx int, cy int) *internal.RendererState {
 	newVal := deepcopy.MustAnything(r.implState).(*internal.RendererState)
-	newVal.Bb = r.implState.Bb.Translate(
-		conv.V2iToV2(r.translateFrom).Sub(conv.V2iToV2(v2i.Vec{X: cx, Y: cy})).Mul(v2.Vec{X: 1, Y: -1}). // Invert Y
-															Div(conv.V2iToV2(r.screenSize)).Mul(r.implState.Bb.Size()))
+	// Snap the translation to whole rendered pixels, so that most of the previous render can be reused (see renderer2)
+	renderSize := conv.V2iToV2(r.renderSize())
+	shift := conv.V2iToV2(r.translateFrom).Sub(conv.V2iToV2(v2i.Vec{X: cx, Y: cy})).Mul(renderSize).Div(conv.V2iToV2(r.screenSize))
+	shift = v2.Vec{X: math.Round(shift.X), Y: -math.Round(shift.Y)} // Invert Y
+	newVal.Bb = r.implState.Bb.Translate(shift.Div(renderSize).Mul(r.implState.Bb.Size()))
 	return newVal
 }
 
@@ -159,6 +196,58 @@ func (r *Renderer) onUpdateInputsSDF3() {
 		r.rerender()
 	}
 	r.onUpdateInputsSDF3RotTrans()
+	// Standard views (Blender-like numpad shortcuts)
+	for key, views := range numpadViews3 {
+		if inpututil.IsKeyJustPressed(key) {
+			view := views[0]
+			if ebiten.IsKeyPressed(ebiten.KeyControl) {
+				view = views[1]
+			}
+			r.implStateLock.Lock()
+			viewCam3(r.implState, r, view)
+			r.implStateLock.Unlock()
+			r.rerender()
+		}
+	}
+	// Projection
+	if inpututil.IsKeyJustPressed(ebiten.KeyO) || inpututil.IsKeyJustPressed(ebiten.KeyKP5) {
+		r.implStateLock.Lock()
+		r.implState.CamOrtho = !r.implState.CamOrtho
+		r.implStateLock.Unlock()
+		r.rerender()
+	}
+	// Anti-aliasing
+	if inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		r.implStateLock.Lock()
+		r.implState.Supersample = nextSupersample(r.implState.Supersample)
+		r.implStateLock.Unlock()
+		r.rerender()
+	}
+	// Section plane
+	if inpututil.IsKeyJustPressed(ebiten.KeyX) {
+		r.implStateLock.Lock()
+		if ebiten.IsKeyPressed(ebiten.KeyShift) { // Show the other half instead
+			r.implState.ClipNormal = r.implState.ClipNormal.Neg()
+		} else {
+			if r.implState.ClipNormal == (v3.Vec{}) {
+				r.clipMode = 0 // Always start with the first plane
+			}
+			r.clipMode = (r.clipMode + 1) % len(clipModes3)
+			nextClipPlane3(r.implState, r.clipMode)
+		}
+		r.implStateLock.Unlock()
+		r.rerender()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeftBracket) || inpututil.IsKeyJustPressed(ebiten.KeyRightBracket) {
+		r.implStateLock.Lock()
+		step := r.impl.BoundingBox().Size().Length() / 50
+		if inpututil.IsKeyJustPressed(ebiten.KeyLeftBracket) {
+			step = -step
+		}
+		r.implState.ClipPoint = r.implState.ClipPoint.Add(r.implState.ClipNormal.MulScalar(step))
+		r.implStateLock.Unlock()
+		r.rerender()
+	}
 	// Reset camera transform
 	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
 		r.implStateLock.Lock()
@@ -168,6 +257,14 @@ func (r *Renderer) onUpdateInputsSDF3() {
 	}
 }
 
+// numpadViews3 maps the numpad keys to the standard views (without and with the Control key pressed)
+var numpadViews3 = map[ebiten.Key][2]View3{
+	ebiten.KeyKP1: {View3Front, View3Back},
+	ebiten.KeyKP3: {View3Right, View3Left},
+	ebiten.KeyKP7: {View3Top, View3Bottom},
+	ebiten.KeyKP0: {View3Isometric, View3Isometric},
+}
+
 func (r *Renderer) onUpdateInputsSDF3RotTrans() {
 	// Rotation + Translation
 	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonMiddle) || len(inpututil.JustPressedTouchIDs()) > 0 {
@@ -238,22 +335,58 @@ func (r *Renderer) drawUI(screen *ebiten.Image) {
 	// Notify when rendering
 	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Millisecond)
 	defer cancelFunc()
+	stats := r.stats.get()
 	if r.renderingLock.RTryLock(ctx) {
 		r.renderingLock.RUnlock()
 	} else {
-		drawDefaultTextWithShadow(screen, "Rendering...", 5, 5+12, color.RGBA{R: 255, A: 255})
+		drawDefaultTextWithShadow(screen, fmt.Sprintf("Rendering... %.0f%%", 100*stats.Progress()), 5, 5+12, color.RGBA{R: 255, A: 255})
 	}
 
 	// Draw current state and controls
 	r.implStateLock.RLock()
 	defer r.implStateLock.RUnlock()
-	msgFmt := "TPS: %0.2f/%d\nResolution: %.2f [+ or = / -]\nColor: %d [C]\nBoxes: %t [B]\nReset camera [R]"
-	msgValues := []interface{}{ebiten.CurrentTPS(), ebiten.MaxTPS(), 1 / float64(r.implState.ResInv), r.implState.ColorMode, r.implState.DrawBbs}
+	if time.Now().Before(r.noticeUntil) {
+		drawDefaultTextWithShadow(screen, r.notice, 5, 5+12+16, color.RGBA{R: 255, G: 255, A: 255})
+	}
+	if r.showStats {
+		msg := stats.String()
+		drawDefaultTextWithShadow(screen, msg, r.screenSize.X-text.BoundString(defaultFont, msg).Size().X-5, 5+12, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	}
+	errorsY := 5 + 12 + 32
+	if r.childStatus != childStatusNone {
+		statusColor := color.RGBA{G: 255, A: 255}
+		switch r.childStatus {
+		case childStatusBuilding, childStatusStarting:
+			statusColor = color.RGBA{R: 255, G: 255, A: 255}
+		case childStatusFailed, childStatusCrashed:
+			statusColor = color.RGBA{R: 255, A: 255}
+		}
+		msg := "Code: " + r.childStatus.String()
+		if r.childStatus == childStatusConnected {
+			msg += " (" + formatChildTimes(r.childBuildTime, r.childStartTime) + ")"
+		}
+		if len(r.childErrors) > 0 { // Keep showing the errors until the next successful build
+			msg += "\n" + strings.Join(r.childErrors, "\n")
+		}
+		drawDefaultTextWithShadow(screen, msg, 5, errorsY, statusColor)
+		errorsY += text.BoundString(defaultFont, msg).Size().Y + 16
+	}
+	if len(r.renderErrors) > 0 { // Keep showing the errors until the next successful render
+		msg := "Render error:\n" + strings.Join(r.renderErrors, "\n")
+		drawDefaultTextWithShadow(screen, msg, 5, errorsY, color.RGBA{R: 255, A: 255})
+	}
+	msgFmt := "TPS: %0.2f/%d\nResolution: %.2f [+ or = / -]\nColor: %d [C]\nBoxes: %t [B]\nStats: %t [I]\nReset camera [R]\nBookmarks: save [Ctrl+1-9], recall [1-9]"
+	msgValues := []interface{}{ebiten.CurrentTPS(), ebiten.MaxTPS(), 1 / float64(r.implState.ResInv), r.implState.ColorMode, r.implState.DrawBbs, r.showStats}
 	switch r.implDimCache {
 	case 2:
 		msgFmt = "SDF2 Renderer\n=============\n" + msgFmt + "\nTranslate cam [MiddleMouse]\nZoom cam [MouseWheel]"
 	case 3:
-		msgFmt = "SDF3 Renderer\n=============\n" + msgFmt + "\nRotate cam [MiddleMouse]\nTranslate cam [Shift+MiddleMouse]\nZoom cam [MouseWheel]"
+		msgFmt = "SDF3 Renderer\n=============\n" + msgFmt + "\nRotate cam [MiddleMouse]\nTranslate cam [Shift+MiddleMouse]\nZoom cam [MouseWheel]\nProjection: %s [O or KP5]\nFront/right/top view [KP1/KP3/KP7 (+Ctrl: opposite)]\nIsometric view [KP0]\nSection: %s [X (+Shift: flip), move [ / ]]\nAnti-aliasing: %s [A]"
+		projection := "perspective"
+		if r.implState.CamOrtho {
+			projection = "orthographic"
+		}
+		msgValues = append(msgValues, projection, clipPlaneName(r.implState), supersampleName(r.implState.Supersample))
 	}
 	msg := fmt.Sprintf(msgFmt, msgValues...)
 	boundString := text.BoundString(defaultFont, msg)
@@ -0,0 +1,163 @@
+package internal
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"image"
+	"io"
+)
+
+// The supported frame encodings (negotiated with RendererService.Hello)
+const (
+	FrameEncodingDeltaDeflate = "delta+deflate" // FrameEncodingDelta compressed with DEFLATE (for slow connections)
+	FrameEncodingDelta        = "delta"         // Only the runs of pixels that changed since the previous frame of the render
+	FrameEncodingRaw          = "raw"           // All pixels, uncompressed
+)
+
+// FrameEncodings lists all the frame encodings supported by this version.
+var FrameEncodings = []string{FrameEncodingDeltaDeflate, FrameEncodingDelta, FrameEncodingRaw}
+
+// Frame is an internal struct that has to be exported for RPC.
+// It is an encoded (partial or full) render.
+type Frame struct {
+	Encoding      string
+	Width, Height int
+	Data          []byte
+}
+
+// FrameEncoder encodes the frames of a single render, remembering the previous frame to only send the differences.
+type FrameEncoder struct {
+	encoding string
+	prev     []byte // The pixels of the previous frame (nil means all pixels are transparent)
+}
+
+// NewFrameEncoder see FrameEncoder
+func NewFrameEncoder(encoding string) *FrameEncoder {
+	return &FrameEncoder{encoding: encoding}
+}
+
+// Encode encodes the next frame of the render. The image must not be modified afterwards, as it is kept as a reference.
+func (e *FrameEncoder) Encode(img *image.RGBA) (*Frame, error) {
+	size := img.Rect.Size()
+	frame := &Frame{Encoding: e.encoding, Width: size.X, Height: size.Y}
+	if len(e.prev) != len(img.Pix) {
+		e.prev = nil
+	}
+	switch e.encoding {
+	case FrameEncodingRaw:
+		frame.Data = img.Pix
+	case FrameEncodingDelta:
+		frame.Data = encodeDelta(e.prev, img.Pix)
+	case FrameEncodingDeltaDeflate:
+		var buf bytes.Buffer
+		w, err := flate.NewWriter(&buf, flate.BestSpeed)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = w.Write(encodeDelta(e.prev, img.Pix)); err != nil {
+			return nil, err
+		}
+		if err = w.Close(); err != nil {
+			return nil, err
+		}
+		frame.Data = buf.Bytes()
+	default:
+		return nil, errors.New("unknown frame encoding: " + e.encoding)
+	}
+	e.prev = img.Pix
+	return frame, nil
+}
+
+// FrameDecoder decodes the frames of a single render (see FrameEncoder).
+type FrameDecoder struct {
+	cur *image.RGBA
+}
+
+// Decode decodes the next frame of the render, returning a new image.
+func (d *FrameDecoder) Decode(frame *Frame) (*image.RGBA, error) {
+	if frame.Width < 0 || frame.Height < 0 {
+		return nil, errors.New("invalid frame size")
+	}
+	if d.cur == nil || d.cur.Rect.Dx() != frame.Width || d.cur.Rect.Dy() != frame.Height {
+		d.cur = image.NewRGBA(image.Rect(0, 0, frame.Width, frame.Height))
+	}
+	switch frame.Encoding {
+	case FrameEncodingRaw:
+		if len(frame.Data) != len(d.cur.Pix) {
+			return nil, errors.New("invalid raw frame size")
+		}
+		copy(d.cur.Pix, frame.Data)
+	case FrameEncodingDelta:
+		if err := decodeDelta(d.cur.Pix, frame.Data); err != nil {
+			return nil, err
+		}
+	case FrameEncodingDeltaDeflate:
+		data, err := io.ReadAll(flate.NewReader(bytes.NewReader(frame.Data)))
+		if err != nil {
+			return nil, err
+		}
+		if err = decodeDelta(d.cur.Pix, data); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("unknown frame encoding: " + frame.Encoding)
+	}
+	res := image.NewRGBA(d.cur.Rect) // The caller may keep the image while we decode the next frame
+	copy(res.Pix, d.cur.Pix)
+	return res, nil
+}
+
+// encodeDelta encodes the pixels that changed as a sequence of runs: the number of unchanged pixels to skip and the
+// number of changed pixels that follow (both as uvarints), followed by the changed pixels.
+func encodeDelta(prev, cur []byte) []byte {
+	var res []byte
+	var varint [binary.MaxVarintLen64]byte
+	changed := func(i int) bool {
+		if prev == nil {
+			return cur[i] != 0 || cur[i+1] != 0 || cur[i+2] != 0 || cur[i+3] != 0
+		}
+		return cur[i] != prev[i] || cur[i+1] != prev[i+1] || cur[i+2] != prev[i+2] || cur[i+3] != prev[i+3]
+	}
+	runEnd := 0
+	for i := 0; i < len(cur); i += 4 {
+		if !changed(i) {
+			continue
+		}
+		runStart := i
+		for i < len(cur) && changed(i) {
+			i += 4
+		}
+		res = append(res, varint[:binary.PutUvarint(varint[:], uint64((runStart-runEnd)/4))]...)
+		res = append(res, varint[:binary.PutUvarint(varint[:], uint64((i-runStart)/4))]...)
+		res = append(res, cur[runStart:i]...)
+		runEnd = i
+	}
+	return res
+}
+
+// decodeDelta applies the runs of changed pixels (see encodeDelta) to the given pixels.
+func decodeDelta(pix, data []byte) error {
+	r := bytes.NewReader(data)
+	pos := 0
+	for r.Len() > 0 {
+		skip, err := binary.ReadUvarint(r)
+		if err != nil {
+			return err
+		}
+		count, err := binary.ReadUvarint(r)
+		if err != nil {
+			return err
+		}
+		if skip > uint64(len(pix)-pos)/4 || count > uint64(len(pix)-pos)/4-skip {
+			return errors.New("invalid delta frame: out of bounds")
+		}
+		pos += int(skip) * 4
+		if _, err = io.ReadFull(r, pix[pos:pos+int(count)*4]); err != nil {
+			return err
+		}
+		pos += int(count) * 4
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func testFrameEncoding(t *testing.T, encoding string) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 32))
+	encoder := NewFrameEncoder(encoding)
+	decoder := &FrameDecoder{}
+	for step := 0; step < 4; step++ {
+		next := image.NewRGBA(img.Rect) // The encoder keeps a reference to the previous frame
+		copy(next.Pix, img.Pix)
+		img = next
+		for i := 0; i < 100; i++ { // Render some pixels of the image
+			img.SetRGBA((step*100+i*7)%64, (step*100+i*13)%32, color.RGBA{R: uint8(step), G: uint8(i), B: 255, A: 255})
+		}
+		frame, err := encoder.Encode(img)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := decoder.Decode(frame)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded.Pix, img.Pix) {
+			t.Fatalf("decoded frame %d does not match the encoded one", step)
+		}
+		if encoding != FrameEncodingRaw && len(frame.Data) >= len(img.Pix)/2 {
+			t.Errorf("expected the frame %d to only contain the changed pixels, but it takes %d bytes", step, len(frame.Data))
+		}
+	}
+}
+
+func TestFrameEncodingRaw(t *testing.T) {
+	testFrameEncoding(t, FrameEncodingRaw)
+}
+
+func TestFrameEncodingDelta(t *testing.T) {
+	testFrameEncoding(t, FrameEncodingDelta)
+}
+
+func TestFrameEncodingDeltaDeflate(t *testing.T) {
+	testFrameEncoding(t, FrameEncodingDeltaDeflate)
+}
+
+func TestFrameDecoderInvalid(t *testing.T) {
+	decoder := &FrameDecoder{}
+	for _, frame := range []*Frame{
+		{Encoding: FrameEncodingRaw, Width: 2, Height: 2, Data: []byte{1, 2, 3}},
+		{Encoding: FrameEncodingDelta, Width: 2, Height: 2, Data: []byte{3, 2, 1, 1, 1, 1, 1, 1, 1, 1}},
+		{Encoding: FrameEncodingDelta, Width: 2, Height: 2, Data: []byte{0, 1, 1}},
+		{Encoding: "pigeon", Width: 2, Height: 2},
+	} {
+		if _, err := decoder.Decode(frame); err == nil {
+			t.Errorf("expected an error decoding %#v", frame)
+		}
+	}
+}
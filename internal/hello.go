@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"fmt"
+	"log"
+)
+
+// ProtocolVersion must be increased on every incompatible change to the types exchanged between the renderer and the
+// new code (like RendererState or RemoteRenderArgs), including new required RPC methods and fields.
+//
+// History: 1: initial handshake; 2: Ping, RemoteRenderResults.Stats, RemoteRenderArgs.Headless and RendererState.Supersample and Progressive.
+const ProtocolVersion = 2
+
+// CapabilityFrameEncodingPrefix is the prefix of the capabilities that list the supported FrameEncodings.
+const CapabilityFrameEncodingPrefix = "frame-encoding:"
+
+// Hello is an internal struct that has to be exported for RPC.
+// It is exchanged on connection to check that both processes understand each other, and to negotiate optional features.
+type Hello struct {
+	ProtocolVersion int
+	Capabilities    []string
+}
+
+// NewHello describes the protocol version and optional features supported by this process.
+func NewHello() Hello {
+	var capabilities []string
+	for _, encoding := range FrameEncodings {
+		capabilities = append(capabilities, CapabilityFrameEncodingPrefix+encoding)
+	}
+	return Hello{ProtocolVersion: ProtocolVersion, Capabilities: capabilities}
+}
+
+// CheckCompatible returns an error if the process that sent this Hello can not be used by this one.
+func (h Hello) CheckCompatible() error {
+	if h.ProtocolVersion != ProtocolVersion {
+		return fmt.Errorf("incompatible sdfx-ui versions (protocol version %d, but this renderer uses %d): "+
+			"restart the renderer after updating sdfx-ui", h.ProtocolVersion, ProtocolVersion)
+	}
+	return nil
+}
+
+// HasCapability checks if the process that sent this Hello supports an optional feature.
+func (h Hello) HasCapability(capability string) bool {
+	for _, c := range h.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Hello is an internal method that has to be exported for RPC.
+// Hello receives the parent's Hello and returns the child's, which is checked by the parent.
+// It fails if the parent is not compatible, so that it does not try to use this process.
+func (d *RendererService) Hello(parent Hello, out *Hello) error {
+	if err := parent.CheckCompatible(); err != nil {
+		log.Println("[DevRenderer] RendererService.Hello:", err)
+		return err
+	}
+	*out = NewHello()
+	return nil
+}
@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"net"
+	"net/rpc"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRendererService_Hello(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	go NewDevRendererService(nil, make(chan os.Signal, 1)).ServeConn(serverConn)
+	client := rpc.NewClient(clientConn)
+	defer func() { _ = client.Close() }()
+	var hello Hello
+	if err := client.Call("RendererService.Hello", NewHello(), &hello); err != nil {
+		t.Fatal(err)
+	}
+	if err := hello.CheckCompatible(); err != nil {
+		t.Fatal(err)
+	}
+	if !hello.HasCapability(CapabilityFrameEncodingPrefix + FrameEncodingDelta) {
+		t.Fatalf("expected the delta frame encoding to be supported, got %v", hello.Capabilities)
+	}
+	// An incompatible parent is rejected by the child
+	oldParent := NewHello()
+	oldParent.ProtocolVersion--
+	if err := client.Call("RendererService.Hello", oldParent, &hello); err == nil || !strings.Contains(err.Error(), "incompatible") {
+		t.Fatalf("expected the child to reject an incompatible parent, got %v", err)
+	}
+}
+
+func TestHello_CheckCompatible(t *testing.T) {
+	hello := NewHello()
+	hello.ProtocolVersion++
+	if err := hello.CheckCompatible(); err == nil || !strings.Contains(err.Error(), "incompatible") {
+		t.Fatalf("expected an incompatibility error, got %v", err)
+	}
+	if hello.HasCapability("pigeon") {
+		t.Fatalf("unexpected capability")
+	}
+}
@@ -3,6 +3,7 @@ package internal
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/barkimedes/go-deepcopy"
 	"github.com/deadsy/sdfx/sdf"
 	"github.com/deadsy/sdfx/vec/v2i"
@@ -10,7 +11,9 @@ import (
 	"log"
 	"net/rpc"
 	"os"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -25,6 +28,11 @@ type RendererService struct {
 	renders                     chan *RemoteRenderResults
 	done                        chan os.Signal
 	reflectTree                 *ReflectTree
+	frameEncoder                *FrameEncoder               // nil if the parent does not support encoded frames
+	renderStats                 atomic.Pointer[RenderStats] // The statistics of the current render (read by Ping)
+	lastProgress                atomic.Int64                // UnixNano of the last progress of the current render (0 if not rendering)
+	lastWork                    int64                       // The RenderStats.work seen by the last Ping
+	lastWorkLock                sync.Mutex                  // Ping may be called concurrently
 }
 
 // NewDevRendererService see RendererService
@@ -74,8 +82,10 @@ func (d *RendererService) ColorModes(_ int, out *int) error {
 //
 //goland:noinspection GoDeprecation
 type RemoteRenderArgs struct {
-	RenderSize v2i.Vec
-	State      *RendererState
+	RenderSize    v2i.Vec
+	State         *RendererState
+	FrameEncoding string // How to send the rendered images: one of FrameEncodings (see Hello), or "" to use RenderedImg
+	Headless      bool   // See RenderArgs.Headless
 }
 
 // RemoteRenderResults is an internal struct that has to be exported for RPC.
@@ -83,8 +93,11 @@ type RemoteRenderArgs struct {
 //goland:noinspection GoDeprecation
 type RemoteRenderResults struct {
 	IsPartial   bool
-	RenderedImg *image.RGBA
+	RenderedImg *image.RGBA // Only set if no RemoteRenderArgs.FrameEncoding was requested
+	Frame       *Frame      // Only set if a RemoteRenderArgs.FrameEncoding was requested
 	NewState    *RendererState
+	Stats       RenderStatsSnapshot // The work done so far by the render
+	err         error               // The render failed (returned by RenderGet instead of the results)
 }
 
 // RenderStart is an internal method that has to be exported for RPC.
@@ -109,6 +122,12 @@ loop: // Wait for previous renders to be properly completed/cancelled before con
 	d.cachedRenderLock.Lock()
 	d.renderCtx = newCtx
 	d.renders = make(chan *RemoteRenderResults)
+	renderStats := &RenderStats{}
+	d.renderStats.Store(renderStats)
+	d.frameEncoder = nil
+	if args.FrameEncoding != "" {
+		d.frameEncoder = NewFrameEncoder(args.FrameEncoding)
+	}
 	if d.reflectTree == nil {
 		_ = d.ReflectTree(sdf.Box3{}, d.reflectTree)
 	}
@@ -116,9 +135,11 @@ loop: // Wait for previous renders to be properly completed/cancelled before con
 	d.cachedRenderLock.Unlock()
 	partialRenders := make(chan *image.RGBA)
 	partialRendersFinish := make(chan struct{})
+	d.lastProgress.Store(time.Now().UnixNano())
 	go func() { // Start processing partial renders as requested (will silently drop it if not requested)
 	loop:
 		for partialRender := range partialRenders {
+			d.lastProgress.Store(time.Now().UnixNano())
 			select {
 			case <-d.renderCtx.Done():
 				log.Println("[DevRenderer] partialRender cancel")
@@ -127,6 +148,7 @@ loop: // Wait for previous renders to be properly completed/cancelled before con
 				IsPartial:   true,
 				RenderedImg: partialRender,
 				NewState:    args.State,
+				Stats:       renderStats.Snapshot(),
 			}:
 			default:
 			}
@@ -135,24 +157,33 @@ loop: // Wait for previous renders to be properly completed/cancelled before con
 	}()
 	go func() { // spawn the blocking render in a different goroutine
 		fullRender := image.NewRGBA(image.Rect(0, 0, args.RenderSize.X, args.RenderSize.Y))
-		err := d.impl.Render(&RenderArgs{
+		err := renderRecover(d.impl, &RenderArgs{
 			Ctx:              d.renderCtx,
 			State:            args.State,
 			StateLock:        d.stateLock,
 			CachedRenderLock: d.cachedRenderLock,
 			PartialRenders:   partialRenders,
 			FullRender:       fullRender,
+			Stats:            renderStats,
+			Headless:         args.Headless,
 		})
+		d.lastProgress.Store(0)
 		if err != nil {
 			log.Println("[DevRenderer] RendererService.Render error:", err)
 		}
-		<-partialRendersFinish // Make sure all partial renders are sent before the full render
-		if err == nil {        // Now we can send the full render
+		<-partialRendersFinish                      // Make sure all partial renders are sent before the full render
+		if err != nil && d.renderCtx.Err() == nil { // Report the error to the parent (unless it was cancelled)
+			select {
+			case d.renders <- &RemoteRenderResults{err: err}:
+			case <-d.renderCtx.Done():
+			}
+		} else if err == nil { // Now we can send the full render
 			select {
 			case d.renders <- &RemoteRenderResults{
 				IsPartial:   false,
 				RenderedImg: fullRender,
 				NewState:    args.State,
+				Stats:       renderStats.Snapshot(),
 			}:
 			case <-d.renderCtx.Done():
 			}
@@ -175,11 +206,25 @@ func (d *RendererService) RenderGet(_ int, out *RemoteRenderResults) error {
 		if !ok {
 			return errNoRenderRunning
 		}
+		if read.err != nil {
+			return read.err
+		}
 		out.IsPartial = read.IsPartial
+		out.Stats = read.Stats
 		d.cachedRenderLock.RLock() // Need to perform a copy of the image to avoid races with the encoder task
-		out.RenderedImg = image.NewRGBA(read.RenderedImg.Rect)
-		copy(out.RenderedImg.Pix, read.RenderedImg.Pix)
+		renderedImg := image.NewRGBA(read.RenderedImg.Rect)
+		copy(renderedImg.Pix, read.RenderedImg.Pix)
+		frameEncoder := d.frameEncoder
 		d.cachedRenderLock.RUnlock()
+		if frameEncoder != nil {
+			frame, err := frameEncoder.Encode(renderedImg)
+			if err != nil {
+				return err
+			}
+			out.Frame = frame
+		} else {
+			out.RenderedImg = renderedImg
+		}
 		d.stateLock.RLock()
 		out.NewState = deepcopy.MustAnything(read.NewState).(*RendererState)
 		d.stateLock.RUnlock()
@@ -189,6 +234,52 @@ func (d *RendererService) RenderGet(_ int, out *RemoteRenderResults) error {
 	}
 }
 
+// renderRecover renders, converting panics (e.g. a bug in the SDF) into errors with the stack trace
+func renderRecover(impl DevRendererImpl, args *RenderArgs) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic while rendering: %v\n%s", rec, debug.Stack())
+			if args.PartialRenders != nil { // It may not have been closed by the implementation
+				func() {
+					defer func() { _ = recover() }() // Already closed
+					close(args.PartialRenders)
+				}()
+			}
+		}
+	}()
+	return impl.Render(args)
+}
+
+// PingResults is an internal struct that has to be exported for RPC.
+type PingResults struct {
+	RenderStalled time.Duration // How long the current render has been running without any progress (0 if not rendering)
+}
+
+// Ping is an internal method that has to be exported for RPC.
+// Ping is used to check that the service is still responsive and the current render is not stuck.
+// Renders make progress when they send partial renders and when their statistics change (see RenderStats), and those
+// that can not report any progress (see RenderStats.SetNoProgress) are never considered stuck.
+func (d *RendererService) Ping(_ int, out *PingResults) error {
+	d.lastWorkLock.Lock()
+	defer d.lastWorkLock.Unlock()
+	lastProgress := d.lastProgress.Load()
+	if lastProgress == 0 { // Not rendering
+		return nil
+	}
+	stats := d.renderStats.Load()
+	if stats.NoProgress() {
+		return nil
+	}
+	if work := stats.work(); work != d.lastWork {
+		d.lastWork = work
+		// Unless the render finished meanwhile
+		d.lastProgress.CompareAndSwap(lastProgress, time.Now().UnixNano())
+		return nil
+	}
+	out.RenderStalled = time.Since(time.Unix(0, lastProgress))
+	return nil
+}
+
 // RenderCancel is an internal struct that has to be exported for RPC.
 // RenderCancel cancels the current rendering. It will always succeed with no error.
 func (d *RendererService) RenderCancel(_ int, _ *int) error {
@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"github.com/deadsy/sdfx/sdf"
+	"github.com/deadsy/sdfx/vec/v2i"
+	"net"
+	"net/rpc"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// panicRenderer is a DevRendererImpl that panics while rendering
+type panicRenderer struct{}
+
+func (p *panicRenderer) Dimensions() int           { return 3 }
+func (p *panicRenderer) BoundingBox() sdf.Box3     { return sdf.Box3{} }
+func (p *panicRenderer) ReflectTree() *ReflectTree { return &ReflectTree{} }
+func (p *panicRenderer) ColorModes() int           { return 1 }
+func (p *panicRenderer) Render(_ *RenderArgs) error {
+	var s sdf.SDF3
+	s.Evaluate(s.BoundingBox().Center()) // nil SDF deep in the tree
+	return nil
+}
+
+func TestRendererService_RenderPanic(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	go NewDevRendererService(&panicRenderer{}, make(chan os.Signal, 1)).ServeConn(serverConn)
+	client := rpc.NewClient(clientConn)
+	defer func() { _ = client.Close() }()
+	var ignoreMe int
+	err := client.Call("RendererService.RenderStart", RemoteRenderArgs{RenderSize: v2i.Vec{X: 4, Y: 4}, State: &RendererState{}}, &ignoreMe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res RemoteRenderResults
+	err = client.Call("RendererService.RenderGet", ignoreMe, &res)
+	if err == nil || !strings.Contains(err.Error(), "panic while rendering") || !strings.Contains(err.Error(), "goroutine") {
+		t.Fatalf("expected the panic and its stack trace as an error, got %v", err)
+	}
+	// The service must still be alive
+	var ping PingResults
+	if err = client.Call("RendererService.Ping", 0, &ping); err != nil {
+		t.Fatal(err)
+	}
+	if ping.RenderStalled != 0 {
+		t.Fatalf("expected no render in progress, got %v", ping.RenderStalled)
+	}
+}
+
+// funcRenderer is a DevRendererImpl that renders by calling a function
+type funcRenderer func(args *RenderArgs) error
+
+func (f funcRenderer) Dimensions() int               { return 3 }
+func (f funcRenderer) BoundingBox() sdf.Box3         { return sdf.Box3{} }
+func (f funcRenderer) ReflectTree() *ReflectTree     { return &ReflectTree{} }
+func (f funcRenderer) ColorModes() int               { return 1 }
+func (f funcRenderer) Render(args *RenderArgs) error { return f(args) }
+
+func TestRendererService_PingStalled(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		render  funcRenderer
+		stalled bool
+	}{
+		{"no progress", func(args *RenderArgs) error {
+			args.Stats.Start(1)
+			<-args.Ctx.Done()
+			return args.Ctx.Err()
+		}, true},
+		{"stats progress", func(args *RenderArgs) error {
+			args.Stats.Start(1000)
+			for args.Ctx.Err() == nil {
+				args.Stats.AddPixelsDone(1)
+				time.Sleep(time.Millisecond)
+			}
+			return args.Ctx.Err()
+		}, false},
+		{"no progress reported", func(args *RenderArgs) error {
+			args.Stats.Start(1)
+			args.Stats.SetNoProgress()
+			<-args.Ctx.Done()
+			return args.Ctx.Err()
+		}, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			serverConn, clientConn := net.Pipe()
+			go NewDevRendererService(test.render, make(chan os.Signal, 1)).ServeConn(serverConn)
+			client := rpc.NewClient(clientConn)
+			defer func() { _ = client.Close() }()
+			var ignoreMe int
+			err := client.Call("RendererService.RenderStart", RemoteRenderArgs{RenderSize: v2i.Vec{X: 4, Y: 4}, State: &RendererState{}}, &ignoreMe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = client.Call("RendererService.RenderCancel", ignoreMe, &ignoreMe) }()
+			time.Sleep(50 * time.Millisecond)
+			var ping PingResults
+			if err = client.Call("RendererService.Ping", 0, &ping); err != nil {
+				t.Fatal(err)
+			}
+			if stalled := ping.RenderStalled >= 50*time.Millisecond; stalled != test.stalled {
+				t.Fatalf("expected stalled=%v, got %v", test.stalled, ping.RenderStalled)
+			}
+		})
+	}
+}
@@ -31,12 +31,16 @@ type RendererState struct {
 	ResInv      int          // How detailed is the image: number screen pixels for each pixel rendered (SDF2: use a power of two)
 	DrawBbs     bool         // Whether to show all bounding boxes (useful for debugging subtraction/intersection of SDFs)
 	ColorMode   int          // The color mode (each render may support multiple modes)
+	Progressive bool         // Render a coarse grid first and refine it in passes, instead of random pixels (see OptMProgressive)
 	ReflectTree *ReflectTree // Cached read-only reflection metadata to have some insight into the SDF hierarchy
 	// SDF2
 	Bb sdf.Box2 // Controls the scale and displacement
 	// SDF3
 	CamCenter                 v3.Vec  // Arc-Ball camera center (the point we are looking at)
 	CamYaw, CamPitch, CamDist float64 // Arc-Ball rotation angles (around CamCenter) and distance from CamCenter
+	CamOrtho                  bool    // Orthographic projection (CamDist controls the extent of the view instead of the distance)
+	ClipPoint, ClipNormal     v3.Vec  // Section plane: hides the half-space in front of ClipPoint towards ClipNormal (zero normal: disabled)
+	Supersample               int     // Anti-aliasing: Supersample x Supersample jittered rays per pixel (<= 1: disabled)
 }
 
 // RenderArgs is internal: do not use outside this project
@@ -46,4 +50,6 @@ type RenderArgs struct {
 	StateLock, CachedRenderLock *sync.RWMutex
 	PartialRenders              chan<- *image.RGBA
 	FullRender                  *image.RGBA
+	Stats                       *RenderStats // Where to count the work done while rendering (may be nil)
+	Headless                    bool         // Not shown on screen (see Renderer.RenderImage): do not reuse other renders
 }
@@ -0,0 +1,115 @@
+package internal
+
+import "sync/atomic"
+
+// RenderStats counts the work done by a render while it is in progress (safe for concurrent use).
+// All methods do nothing on a nil *RenderStats, so implementations do not need to check if anybody is interested.
+type RenderStats struct {
+	pixels, pixelsDone atomic.Int64
+	rays, steps        atomic.Int64
+	misses, errors     atomic.Int64
+	noProgress         atomic.Bool
+}
+
+// RenderStatsSnapshot is an internal struct that has to be exported for RPC.
+// It holds the values of RenderStats at some point of the render.
+type RenderStatsSnapshot struct {
+	Pixels     int64 // The number of pixels of the whole render (including the ones that are rendered again to refine them)
+	PixelsDone int64 // The number of pixels already rendered
+	Rays       int64 // The number of rays cast (one or more per pixel when supersampling, SDF3 only)
+	Steps      int64 // The total raymarching steps of all rays (SDF3 only)
+	Misses     int64 // The rays that did not hit the surface (SDF3 only)
+	Errors     int64 // The rays that ran out of steps before hitting the surface (SDF3 only)
+}
+
+// Start resets the statistics for a new render of the given number of pixels
+func (s *RenderStats) Start(pixels int) {
+	if s == nil {
+		return
+	}
+	s.Set(RenderStatsSnapshot{Pixels: int64(pixels)})
+	s.noProgress.Store(false)
+}
+
+// SetNoProgress marks the current render as not reporting any progress until it finishes (e.g. a single draw call of
+// the whole mesh), so that it is not considered stuck while it is slow
+func (s *RenderStats) SetNoProgress() {
+	if s == nil {
+		return
+	}
+	s.noProgress.Store(true)
+}
+
+// NoProgress returns true if the current render does not report any progress until it finishes (see SetNoProgress)
+func (s *RenderStats) NoProgress() bool {
+	if s == nil {
+		return false
+	}
+	return s.noProgress.Load()
+}
+
+// work returns a value that increases whenever the render makes any progress
+func (s *RenderStats) work() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.pixelsDone.Load() + s.rays.Load()
+}
+
+// AddPixels increases the number of pixels of the render (e.g. pixels that are rendered again to refine them)
+func (s *RenderStats) AddPixels(pixels int) {
+	if s == nil {
+		return
+	}
+	s.pixels.Add(int64(pixels))
+}
+
+// AddPixelsDone increases the number of pixels already rendered
+func (s *RenderStats) AddPixelsDone(pixelsDone int) {
+	if s == nil {
+		return
+	}
+	s.pixelsDone.Add(int64(pixelsDone))
+}
+
+// AddRay records the result of casting a ray (one or more per pixel when supersampling)
+func (s *RenderStats) AddRay(steps int, hit, outOfSteps bool) {
+	if s == nil {
+		return
+	}
+	s.rays.Add(1)
+	s.steps.Add(int64(steps))
+	if outOfSteps {
+		s.errors.Add(1)
+	} else if !hit {
+		s.misses.Add(1)
+	}
+}
+
+// Snapshot returns the current values of the statistics
+func (s *RenderStats) Snapshot() RenderStatsSnapshot {
+	if s == nil {
+		return RenderStatsSnapshot{}
+	}
+	return RenderStatsSnapshot{
+		Pixels:     s.pixels.Load(),
+		PixelsDone: s.pixelsDone.Load(),
+		Rays:       s.rays.Load(),
+		Steps:      s.steps.Load(),
+		Misses:     s.misses.Load(),
+		Errors:     s.errors.Load(),
+	}
+}
+
+// Set overwrites the statistics (e.g. with the ones received from a remote render)
+func (s *RenderStats) Set(snapshot RenderStatsSnapshot) {
+	if s == nil {
+		return
+	}
+	s.pixels.Store(snapshot.Pixels)
+	s.pixelsDone.Store(snapshot.PixelsDone)
+	s.rays.Store(snapshot.Rays)
+	s.steps.Store(snapshot.Steps)
+	s.misses.Store(snapshot.Misses)
+	s.errors.Store(snapshot.Errors)
+}
@@ -0,0 +1,28 @@
+package internal
+
+import "testing"
+
+func TestRenderStats(t *testing.T) {
+	var nilStats *RenderStats // Must be safe to use
+	nilStats.Start(10)
+	nilStats.AddRay(3, true, false)
+	if nilStats.Snapshot() != (RenderStatsSnapshot{}) {
+		t.Fatal("nil statistics should be empty")
+	}
+	stats := &RenderStats{}
+	stats.Start(4)
+	stats.AddRay(3, true, false)
+	stats.AddRay(5, false, false)
+	stats.AddRay(100, false, true)
+	stats.AddPixelsDone(2)
+	stats.AddPixelsDone(1)
+	stats.AddPixels(2)
+	expected := RenderStatsSnapshot{Pixels: 6, PixelsDone: 3, Rays: 3, Steps: 108, Misses: 1, Errors: 1}
+	if got := stats.Snapshot(); got != expected {
+		t.Fatalf("expected %+v, got %+v", expected, got)
+	}
+	stats.Start(8) // Resets everything
+	if got := stats.Snapshot(); got != (RenderStatsSnapshot{Pixels: 8}) {
+		t.Fatalf("expected a reset, got %+v", got)
+	}
+}
@@ -0,0 +1,236 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The supported transports, in order of preference (also used as the prefix of the address given to the child)
+const (
+	TransportFd   = "fd"   // Pipes inherited by the child process as extra file descriptors (not available on Windows)
+	TransportUnix = "unix" // A Unix socket in a temporary directory
+	TransportTCP  = "tcp"  // A loopback TCP port chosen by the OS
+)
+
+// transportReady is sent by the child as soon as it is connected and ready to serve RPC calls.
+const transportReady = byte(1)
+
+// ParentTransport is the parent's side of the connection to a child process (an internal type that has to be exported).
+// The parent owns the listening side before the child is started, so there are no races for free ports.
+type ParentTransport struct {
+	kind, address string
+	listener      net.Listener // TransportUnix and TransportTCP
+	tmpDir        string       // TransportUnix (removed once the child connects or on Close)
+	parentFiles   [2]*os.File  // TransportFd: read and write ends kept by the parent
+	childFiles    [2]*os.File  // TransportFd: read and write ends given to the child
+}
+
+// NewParentTransport prepares the best transport available on this platform, falling back to the next one on errors.
+// TransportFd is only used if inheritFds, as it needs the started process to be the child (or to pass file descriptors
+// 3 and 4 on to it, which is not guaranteed for commands like "go run" that start the child as a grandchild process).
+func NewParentTransport(inheritFds bool) (*ParentTransport, error) {
+	var errs []string
+	for _, kind := range []string{TransportFd, TransportUnix, TransportTCP} {
+		if kind == TransportFd && !inheritFds {
+			continue
+		}
+		t, err := newParentTransport(kind)
+		if err == nil {
+			return t, nil
+		}
+		errs = append(errs, kind+": "+err.Error())
+	}
+	return nil, errors.New("no transport available (" + strings.Join(errs, ", ") + ")")
+}
+
+func newParentTransport(kind string) (*ParentTransport, error) {
+	t := &ParentTransport{kind: kind}
+	switch kind {
+	case TransportFd:
+		if !fdTransportSupported {
+			return nil, errors.New("not supported on this platform")
+		}
+		childRead, parentWrite, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+		parentRead, childWrite, err := os.Pipe()
+		if err != nil {
+			_ = childRead.Close()
+			_ = parentWrite.Close()
+			return nil, err
+		}
+		t.parentFiles = [2]*os.File{parentRead, parentWrite}
+		t.childFiles = [2]*os.File{childRead, childWrite}
+	case TransportUnix:
+		tmpDir, err := os.MkdirTemp("", "sdfx-ui-")
+		if err != nil {
+			return nil, err
+		}
+		t.tmpDir = tmpDir
+		t.address = filepath.Join(tmpDir, "child.sock")
+		t.listener, err = net.Listen("unix", t.address)
+		if err != nil {
+			_ = os.RemoveAll(tmpDir)
+			return nil, err
+		}
+	case TransportTCP:
+		listener, err := net.Listen("tcp", "127.0.0.1:0") // Loopback only, to avoid firewall prompts
+		if err != nil {
+			return nil, err
+		}
+		t.listener = listener
+		t.address = listener.Addr().String()
+	default:
+		return nil, errors.New("unknown transport " + kind)
+	}
+	return t, nil
+}
+
+// Kind returns the type of transport (see TransportFd, TransportUnix and TransportTCP).
+func (t *ParentTransport) Kind() string {
+	return t.kind
+}
+
+// Start configures the command to connect back to this transport (through the given environment variable) and starts it.
+func (t *ParentTransport) Start(cmd *exec.Cmd, envKey string) error {
+	address := t.address
+	if t.kind == TransportFd {
+		firstFd := 3 + len(cmd.ExtraFiles) // Standard input, output and error are always the first 3
+		cmd.ExtraFiles = append(cmd.ExtraFiles, t.childFiles[0], t.childFiles[1])
+		address = strconv.Itoa(firstFd) + "," + strconv.Itoa(firstFd+1)
+	}
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, envKey+"="+t.kind+":"+address)
+	err := cmd.Start()
+	if t.kind == TransportFd { // The child has its own copy now (and we need to detect when it closes them)
+		for _, f := range t.childFiles {
+			if err2 := f.Close(); err2 != nil {
+				log.Println("[DevRenderer] ParentTransport child pipe close error:", err2)
+			}
+		}
+	}
+	return err
+}
+
+// Accept blocks until the child is connected and ready to serve RPC calls.
+// It may be aborted with Close, which must not be called after a successful Accept. All resources are released on errors.
+func (t *ParentTransport) Accept() (io.ReadWriteCloser, error) {
+	var conn io.ReadWriteCloser
+	if t.kind == TransportFd {
+		conn = &pipeConn{r: t.parentFiles[0], w: t.parentFiles[1]}
+	} else {
+		netConn, err := t.listener.Accept()
+		if err != nil {
+			_ = t.closeListener() // May be already closed
+			return nil, err
+		}
+		conn = netConn
+		t.closeListener() // Only one child may connect
+	}
+	var ready [1]byte
+	if _, err := io.ReadFull(conn, ready[:]); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if ready[0] != transportReady {
+		_ = conn.Close()
+		return nil, fmt.Errorf("unexpected first byte from child: %d", ready[0])
+	}
+	return conn, nil
+}
+
+// Close stops waiting for the child to connect, releasing all resources.
+// Resources already released by Accept (e.g. after the child connected, while waiting for it to be ready) are ignored.
+func (t *ParentTransport) Close() error {
+	if t.kind == TransportFd {
+		var err error
+		for _, f := range t.parentFiles {
+			if err2 := f.Close(); err2 != nil && !errors.Is(err2, os.ErrClosed) {
+				err = err2
+			}
+		}
+		return err
+	}
+	return t.closeListener()
+}
+
+func (t *ParentTransport) closeListener() error {
+	err := t.listener.Close()
+	if errors.Is(err, net.ErrClosed) { // Already closed
+		err = nil
+	}
+	if t.tmpDir != "" {
+		if err2 := os.RemoveAll(t.tmpDir); err2 != nil && err == nil {
+			err = err2
+		}
+	}
+	return err
+}
+
+// ChildConnect connects to the parent given the address it set in the environment variable (see ParentTransport.Start),
+// and notifies it that it is ready to serve RPC calls.
+func ChildConnect(address string) (io.ReadWriteCloser, error) {
+	kind, value, ok := strings.Cut(address, ":")
+	if !ok {
+		return nil, errors.New("invalid parent address " + strconv.Quote(address))
+	}
+	var conn io.ReadWriteCloser
+	switch kind {
+	case TransportFd:
+		readFdStr, writeFdStr, ok := strings.Cut(value, ",")
+		readFd, err := strconv.Atoi(readFdStr)
+		if err != nil || !ok {
+			return nil, errors.New("invalid file descriptors " + strconv.Quote(value))
+		}
+		writeFd, err := strconv.Atoi(writeFdStr)
+		if err != nil {
+			return nil, errors.New("invalid file descriptors " + strconv.Quote(value))
+		}
+		conn = &pipeConn{r: os.NewFile(uintptr(readFd), "parent-read"), w: os.NewFile(uintptr(writeFd), "parent-write")}
+	case TransportUnix, TransportTCP:
+		netConn, err := net.Dial(kind, value)
+		if err != nil {
+			return nil, err
+		}
+		conn = netConn
+	default:
+		return nil, errors.New("unknown transport " + strconv.Quote(kind))
+	}
+	if _, err := conn.Write([]byte{transportReady}); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// pipeConn joins the read and write ends of two pipes into a single connection.
+type pipeConn struct {
+	r, w *os.File
+}
+
+func (p *pipeConn) Read(b []byte) (int, error) {
+	return p.r.Read(b)
+}
+
+func (p *pipeConn) Write(b []byte) (int, error) {
+	return p.w.Write(b)
+}
+
+func (p *pipeConn) Close() error {
+	err := p.r.Close()
+	if err2 := p.w.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
@@ -0,0 +1,7 @@
+//go:build windows || js
+// +build windows js
+
+package internal
+
+// fdTransportSupported is false as extra file descriptors are not inherited by child processes on this platform.
+const fdTransportSupported = false
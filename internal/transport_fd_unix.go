@@ -0,0 +1,7 @@
+//go:build !windows && !js
+// +build !windows,!js
+
+package internal
+
+// fdTransportSupported is true as extra file descriptors are inherited by child processes on this platform.
+const fdTransportSupported = true
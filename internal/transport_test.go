@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func testTransport(t *testing.T, kind string) {
+	parent, err := newParentTransport(kind)
+	if err != nil {
+		t.Skip("transport not available:", err)
+	}
+	address := kind + ":" + parent.address
+	if kind == TransportFd { // Simulate the child in this process, using the file descriptors directly
+		address = kind + ":" + strconv.Itoa(int(parent.childFiles[0].Fd())) + "," + strconv.Itoa(int(parent.childFiles[1].Fd()))
+	}
+	accepted := make(chan io.ReadWriteCloser, 1)
+	go func() {
+		conn, err := parent.Accept()
+		if err != nil {
+			t.Error(err)
+		}
+		accepted <- conn
+	}()
+	child, err := ChildConnect(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = child.Close() }()
+	conn := <-accepted
+	if conn == nil {
+		t.FailNow()
+	}
+	defer func() { _ = conn.Close() }()
+	// Check that both directions work
+	if _, err = child.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err = io.ReadFull(conn, buf); err != nil || string(buf) != "ping" {
+		t.Fatalf("unexpected read from child: %q, %v", buf, err)
+	}
+	if _, err = conn.Write([]byte("pong")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = io.ReadFull(child, buf); err != nil || string(buf) != "pong" {
+		t.Fatalf("unexpected read from parent: %q, %v", buf, err)
+	}
+}
+
+func TestTransportFd(t *testing.T) {
+	testTransport(t, TransportFd)
+}
+
+func TestTransportUnix(t *testing.T) {
+	testTransport(t, TransportUnix)
+}
+
+func TestTransportTCP(t *testing.T) {
+	testTransport(t, TransportTCP)
+}
+
+func TestParentTransport_Close(t *testing.T) {
+	parent, err := NewParentTransport(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acceptErr := make(chan error, 1)
+	go func() {
+		_, err := parent.Accept()
+		acceptErr <- err
+	}()
+	if err = parent.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err = <-acceptErr:
+		if err == nil {
+			t.Fatalf("expected an error after closing the transport")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("closing the transport did not abort Accept")
+	}
+}
+
+func TestChildConnectInvalid(t *testing.T) {
+	for _, address := range []string{"", "127.0.0.1:1234", "fd:3", "pigeon:coop"} {
+		if _, err := ChildConnect(address); err == nil {
+			t.Errorf("expected an error for address %q", address)
+		}
+	}
+}
+
+func TestParentTransport_AcceptError(t *testing.T) {
+	parent, err := newParentTransport(TransportUnix)
+	if err != nil {
+		t.Skip("transport not available:", err)
+	}
+	_ = parent.listener.Close() // Make Accept fail
+	if _, err = parent.Accept(); err == nil {
+		t.Fatalf("expected an error accepting on a closed listener")
+	}
+	if _, err = os.Stat(parent.tmpDir); !os.IsNotExist(err) {
+		t.Fatalf("expected the temporary directory to be removed after the error, got %v", err)
+	}
+}
+
+func TestNewParentTransport_noFds(t *testing.T) {
+	parent, err := NewParentTransport(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = parent.Close() }()
+	if parent.Kind() == TransportFd {
+		t.Fatalf("expected a transport that does not need inherited file descriptors")
+	}
+}
+
+func TestParentTransport_CloseAfterAccept(t *testing.T) {
+	for _, kind := range []string{TransportUnix, TransportTCP} {
+		parent, err := newParentTransport(kind)
+		if err != nil {
+			t.Log("transport not available:", err)
+			continue
+		}
+		if err = parent.closeListener(); err != nil { // Like Accept once the child connected
+			t.Fatal(err)
+		}
+		if err = parent.Close(); err != nil {
+			t.Fatalf("%s: closing an already closed listener should not fail, got %v", kind, err)
+		}
+	}
+}
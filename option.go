@@ -11,7 +11,18 @@ type Option = func(r *Renderer)
 
 // NOTE: There are more options defined in impl*.go, all starting with Opt<X>
 
-// OptMRunCommand replaces the default run command (go run -v .) with any other command generator.
+// OptMBuildCommand replaces the default build command (go build -o <binaryPath> .) with any other command generator.
+// It must compile the code to binaryPath, which is then run directly by the renderer.
+// WARNING: Need to run again the main renderer to apply a change of this option.
+func OptMBuildCommand(buildCmd func(binaryPath string) *exec.Cmd) Option {
+	return func(r *Renderer) {
+		r.buildCmd = buildCmd
+	}
+}
+
+// OptMRunCommand replaces the default build pipeline (see OptMBuildCommand) with a command that compiles and runs the
+// code by itself (e.g. go run -v .), so the compile time can not be shown separately. The new code connects back through
+// a Unix socket or a loopback TCP port, as the inherited pipes used by default may not reach it.
 // WARNING: Need to run again the main renderer to apply a change of this option.
 func OptMRunCommand(runCmd func() *exec.Cmd) Option {
 	return func(r *Renderer) {
@@ -20,6 +31,7 @@ func OptMRunCommand(runCmd func() *exec.Cmd) Option {
 }
 
 // OptMWatchFiles replaces the default set of files to watch for changes (["."]).
+// Directories are watched recursively, only for the files matching the patterns set by OptMWatchPatterns.
 // WARNING: Need to run again the main renderer to apply a change of this option.
 func OptMWatchFiles(filePaths []string) Option {
 	return func(r *Renderer) {
@@ -27,6 +39,32 @@ func OptMWatchFiles(filePaths []string) Option {
 	}
 }
 
+// OptMWatchPatterns replaces the glob patterns that select which files inside the watched directories trigger a
+// recompilation (default *.go, go.mod, go.sum and fonts like *.ttf), and which files or directories are ignored
+// (default hidden files, editor backups, "vendor" and "testdata" directories, and build artifacts).
+// The patterns are matched against the base name of each file (see filepath.Match), and a nil slice keeps the default.
+// WARNING: Need to run again the main renderer to apply a change of this option.
+func OptMWatchPatterns(include, exclude []string) Option {
+	return func(r *Renderer) {
+		if include != nil {
+			r.watchInclude = include
+		}
+		if exclude != nil {
+			r.watchExclude = exclude
+		}
+	}
+}
+
+// OptMStateFile changes the file where the viewer state (camera, resolution, color mode...) is saved when the window is
+// closed and restored from on the next start. Relative paths are resolved next to the first watched file
+// (default ".sdfx-ui-state.json"). An empty path disables saving and restoring the state.
+// WARNING: Need to run again the main renderer to apply a change of this option.
+func OptMStateFile(filePath string) Option {
+	return func(r *Renderer) {
+		r.stateFile = filePath
+	}
+}
+
 // OptMBackoff changes the default backoff algorithm used when trying to connect to the new code.
 // WARNING: Need to run again the main renderer to apply a change of this option.
 func OptMBackoff(backOff backoff.BackOff) Option {
@@ -35,6 +73,27 @@ func OptMBackoff(backOff backoff.BackOff) Option {
 	}
 }
 
+// OptMCompressFrames compresses the images sent from the new code to the renderer (default false). Only the pixels
+// that changed since the previous partial render are sent anyway, so this is only useful for slow connections.
+// WARNING: Need to run again the main renderer to apply a change of this option.
+func OptMCompressFrames(compress bool) Option {
+	return func(r *Renderer) {
+		r.compressFrames = compress
+	}
+}
+
+// OptMWatchdog changes how often the new code is checked for crashes (default 2s), and how long it may take to respond
+// or to make progress while rendering (default 1m) before falling back to the last working code (the previous code that
+// completed a render, which is kept running for this). Renders that can not report their progress (Opt3Mesh) are never
+// considered stuck.
+// WARNING: Need to run again the main renderer to apply a change of this option.
+func OptMWatchdog(every, timeout time.Duration) Option {
+	return func(r *Renderer) {
+		r.watchdogEvery = every
+		r.watchdogTimeout = timeout
+	}
+}
+
 // OptMPartialRenderEvery changes the default duration between partial renders (loading a partial render takes a little
 // time and slows down the full render if too frequent).
 // WARNING: Need to run again the main renderer to apply a change of this option.
@@ -68,6 +127,16 @@ func OptMColorMode(colorMode int) Option {
 	}
 }
 
+// OptMProgressive enables progressive rendering (enabled by default): a coarse grid of pixels is rendered first and shown
+// upscaled, and then it is refined in passes until full resolution. Otherwise, pixels are rendered in random order.
+// This only changes the partial renders shown while rendering: the final image is the same.
+// WARNING: Need to run again the main renderer to apply a change of this option.
+func OptMProgressive(progressive bool) Option {
+	return func(r *Renderer) {
+		r.implState.Progressive = progressive
+	}
+}
+
 // OptMSmoothCamera renders camera frames while dragging the mouse if enabled (2D/3D). Disabled by default.
 // WARNING: Need to run again the main renderer to apply a change of this option.
 func OptMSmoothCamera(smoothCamera bool) Option {
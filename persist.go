@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/deadsy/sdfx/sdf"
+	v3 "github.com/deadsy/sdfx/vec/v3"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// defaultStateFile is where the viewer state is saved by default (relative to the watched sources, see OptMStateFile)
+const defaultStateFile = ".sdfx-ui-state.json"
+
+// persistedState is the subset of the renderer's state that is saved between sessions (see OptMStateFile)
+type persistedState struct {
+	Dimensions int // The state is discarded if it was saved for a different type of SDF
+	// SHARED
+	ResInv    int
+	DrawBbs   bool
+	ColorMode int
+	// SDF2
+	Bb sdf.Box2
+	// SDF3
+	CamCenter                 v3.Vec
+	CamYaw, CamPitch, CamDist float64
+	CamOrtho                  bool
+	Supersample               int
+	// Bookmarks saved by the user (overriding the predefined ones), nil for the slots that keep the predefined ones
+	Bookmarks []*Bookmark
+}
+
+// stateFilePath returns the path to the state file, or "" if disabled.
+// Relative paths are resolved next to the first watched source.
+func (r *Renderer) stateFilePath() string {
+	if r.stateFile == "" || filepath.IsAbs(r.stateFile) || len(r.watchFiles) == 0 {
+		return r.stateFile
+	}
+	sourcesDir := r.watchFiles[0]
+	if info, err := os.Stat(sourcesDir); err != nil || !info.IsDir() {
+		sourcesDir = filepath.Dir(sourcesDir)
+	}
+	return filepath.Join(sourcesDir, r.stateFile)
+}
+
+// loadState restores the state saved by a previous session (if any), overriding the defaults
+func (r *Renderer) loadState() {
+	filePath := r.stateFilePath()
+	if filePath == "" {
+		return
+	}
+	bs, err := os.ReadFile(filePath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Println("[DevRenderer] Error reading state file (ignored):", err)
+		}
+		return
+	}
+	var saved persistedState
+	if err = json.Unmarshal(bs, &saved); err != nil {
+		log.Println("[DevRenderer] Error parsing state file (ignored):", err)
+		return
+	}
+	r.implLock.RLock()
+	defer r.implLock.RUnlock()
+	r.implStateLock.Lock()
+	defer r.implStateLock.Unlock()
+	if saved.Dimensions != r.implDimCache {
+		log.Println("[DevRenderer] Ignoring state file saved for a different type of SDF:", filePath)
+		return
+	}
+	if saved.ResInv >= 1 {
+		r.implState.ResInv = saved.ResInv
+	}
+	r.implState.DrawBbs = saved.DrawBbs
+	if saved.ColorMode >= 0 {
+		r.implState.ColorMode = saved.ColorMode % r.impl.ColorModes()
+	}
+	switch saved.Dimensions {
+	case 2:
+		r.implState.Bb = saved.Bb
+	case 3:
+		r.implState.CamCenter = saved.CamCenter
+		r.implState.CamYaw, r.implState.CamPitch, r.implState.CamDist = saved.CamYaw, saved.CamPitch, saved.CamDist
+		r.implState.CamOrtho = saved.CamOrtho
+		r.implState.Supersample = saved.Supersample
+	}
+	for i, bookmark := range saved.Bookmarks {
+		if i < maxBookmarks && bookmark != nil {
+			r.bookmarks[i] = bookmark
+			r.bookmarksSaved[i] = true
+		}
+	}
+	log.Println("[DevRenderer] Restored state from", filePath)
+}
+
+// saveState saves the state for the next session (if enabled)
+func (r *Renderer) saveState() {
+	filePath := r.stateFilePath()
+	if filePath == "" {
+		return
+	}
+	r.implStateLock.RLock()
+	saved := persistedState{
+		Dimensions:  r.implDimCache,
+		ResInv:      r.implState.ResInv,
+		DrawBbs:     r.implState.DrawBbs,
+		ColorMode:   r.implState.ColorMode,
+		Bb:          r.implState.Bb,
+		CamCenter:   r.implState.CamCenter,
+		CamYaw:      r.implState.CamYaw,
+		CamPitch:    r.implState.CamPitch,
+		CamDist:     r.implState.CamDist,
+		CamOrtho:    r.implState.CamOrtho,
+		Supersample: r.implState.Supersample,
+		Bookmarks:   make([]*Bookmark, maxBookmarks),
+	}
+	for i, bookmark := range r.bookmarks {
+		if r.bookmarksSaved[i] { // The predefined bookmarks (see OptMBookmarks) may change before the next session
+			saved.Bookmarks[i] = bookmark
+		}
+	}
+	r.implStateLock.RUnlock()
+	bs, err := json.MarshalIndent(&saved, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filePath, bs, 0644)
+	}
+	if err != nil {
+		log.Println("[DevRenderer] Error saving state file:", err)
+	}
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"github.com/deadsy/sdfx/sdf"
+	v2 "github.com/deadsy/sdfx/vec/v2"
+	v3 "github.com/deadsy/sdfx/vec/v3"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderer_saveLoadState(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	s, _ := sdf.Box3D(v3.Vec{X: 1, Y: 1, Z: 1}, 0.2)
+	r := NewRenderer(s, OptMStateFile(stateFile))
+	r.implState.CamCenter = v3.Vec{X: 1, Y: 2, Z: 3}
+	r.implState.CamYaw, r.implState.CamPitch, r.implState.CamDist = 0.1, 0.2, 5
+	r.implState.CamOrtho = true
+	r.implState.ResInv = 2
+	r.implState.ColorMode = 1
+	r.implState.DrawBbs = true
+	r.implState.Supersample = 2
+	r.saveState()
+
+	r2 := NewRenderer(s, OptMStateFile(stateFile))
+	r2.loadState()
+	if r2.implState.CamCenter != r.implState.CamCenter || r2.implState.CamYaw != r.implState.CamYaw ||
+		r2.implState.CamPitch != r.implState.CamPitch || r2.implState.CamDist != r.implState.CamDist ||
+		r2.implState.CamOrtho != r.implState.CamOrtho || r2.implState.ResInv != r.implState.ResInv ||
+		r2.implState.ColorMode != r.implState.ColorMode || r2.implState.DrawBbs != r.implState.DrawBbs ||
+		r2.implState.Supersample != r.implState.Supersample {
+		t.Fatalf("restored state %#v does not match saved state %#v", r2.implState, r.implState)
+	}
+
+	// A state saved for a SDF3 must not be applied to a SDF2
+	s2 := sdf.Box2D(v2.Vec{X: 1, Y: 1}, 0.25)
+	r3 := NewRenderer(s2, OptMStateFile(stateFile))
+	r3.loadState()
+	if r3.implState.ResInv == r.implState.ResInv {
+		t.Fatalf("the state of a SDF3 should not be restored for a SDF2")
+	}
+}
+
+func TestRenderer_stateFilePath(t *testing.T) {
+	s, _ := sdf.Box3D(v3.Vec{X: 1, Y: 1, Z: 1}, 0.2)
+	if got := NewRenderer(s, OptMStateFile("")).stateFilePath(); got != "" {
+		t.Errorf("expected the state file to be disabled, got %s", got)
+	}
+	if got := NewRenderer(s, OptMWatchFiles([]string{"examples/spiral/main.go"})).stateFilePath(); got != filepath.Join("examples", "spiral", defaultStateFile) {
+		t.Errorf("expected the state file next to the first watched file, got %s", got)
+	}
+}
+
+func TestRenderer_saveLoadStateBookmarks(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	s, _ := sdf.Box3D(v3.Vec{X: 1, Y: 1, Z: 1}, 0.2)
+	r := NewRenderer(s, OptMStateFile(stateFile), OptMBookmarks(&Bookmark{Name: "Old 1"}, &Bookmark{Name: "Old 2"}))
+	r.implState.CamDist = 5
+	r.saveBookmark(1)
+	r.saveState()
+
+	// Only the bookmarks saved by the user override the (possibly changed) predefined ones
+	r2 := NewRenderer(s, OptMStateFile(stateFile), OptMBookmarks(&Bookmark{Name: "New 1"}, &Bookmark{Name: "New 2"}))
+	r2.loadState()
+	if r2.bookmarks[0].Name != "New 1" {
+		t.Fatalf("expected the predefined bookmark to be updated, got %#v", r2.bookmarks[0])
+	}
+	if r2.bookmarks[1].Name != "Old 2" || r2.bookmarks[1].CamDist != 5 {
+		t.Fatalf("expected the bookmark saved by the user to be restored, got %#v", r2.bookmarks[1])
+	}
+	r2.saveState() // Restored bookmarks are still saved by the user
+	r3 := NewRenderer(s, OptMStateFile(stateFile))
+	r3.loadState()
+	if r3.bookmarks[0] != nil || r3.bookmarks[1] == nil || r3.bookmarks[1].CamDist != 5 {
+		t.Fatalf("unexpected bookmarks after saving again: %#v", r3.bookmarks)
+	}
+}
@@ -1,26 +1,46 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/Yeicor/sdfx-ui/internal"
 	"github.com/cenkalti/backoff/v4"
 	"github.com/hajimehoshi/ebiten"
 	"io"
 	"log"
-	"net"
-	"net/http"
 	"net/rpc"
 	"os"
 	"os/exec"
 	"os/signal"
+	"runtime"
 	"strconv"
 	"syscall"
 	"time"
 )
 
-const changeEventThrottle = 100 * time.Millisecond
+// changeDebounce is how long to wait after the last change event before building (IDEs tend to generate events in bulk)
+const changeDebounce = 100 * time.Millisecond
 
 func (r *Renderer) runRenderer(runCmdF func() *exec.Cmd, watchFiles []string) error {
+	r.loadState()
+	defer r.saveState() // Deferred first to run after the file watcher is closed
+	defer r.exitOnSignals()()
+	var buildDir string // Where the new code is compiled to, if not using a custom run command
+	if len(watchFiles) > 0 && runCmdF == nil {
+		var err error
+		if buildDir, err = os.MkdirTemp("", "sdfx-ui-"); err != nil {
+			log.Println("Error creating build directory (won't update on changes):", err)
+			watchFiles = nil
+		} else {
+			defer func() {
+				if err := os.RemoveAll(buildDir); err != nil {
+					log.Println("[DevRenderer] Build directory removal error:", err)
+				}
+			}()
+		}
+	}
+	defer r.children.shutdown(childShutdownTimeout) // Stop the new code before removing its binaries
 	if len(watchFiles) > 0 {
 		watcher, err := newFsWatcher()
 		if err != nil {
@@ -33,22 +53,46 @@ func (r *Renderer) runRenderer(runCmdF func() *exec.Cmd, watchFiles []string) er
 				}
 			}(watcher)
 
+			filter := newWatchFilter(r.watchInclude, r.watchExclude, watchFiles)
 			go func() {
-				var runCmd *exec.Cmd
-				lastEvent := time.Now()
+				debounce := time.NewTimer(changeDebounce)
+				debounce.Stop()
+				buildsCtx, cancelBuilds := context.WithCancel(context.Background()) // Cancels any build when no longer watching
+				defer cancelBuilds()
+				cancelBuild := func() {}
+				buildDone := make(chan struct{}) // Closed when the latest build finishes
+				close(buildDone)
 				for {
 					select {
 					case event, ok := <-watcher.Events:
 						if !ok {
 							return
 						}
-						if time.Since(lastEvent) < changeEventThrottle {
-							log.Println("[DevRenderer] Change detected (but throttled)!", event)
-							continue // Events tend to be generated in bulk if using an IDE, skip them if too close together
+						if event.Op&fsCreate != 0 && !filter.excluded(event.Name) { // Watch new subdirectories too
+							if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+								if err = filter.addWatch(watcher, event.Name); err != nil {
+									log.Println("Error watching directory", event.Name, "-", err)
+								}
+							}
+						}
+						if !filter.matches(event.Name) {
+							continue // Not a source file (e.g. build artifacts or editor swap files)
 						}
 						log.Println("[DevRenderer] Change detected!", event)
-						runCmd = r.rendererSwapChild(runCmd, runCmdF)
-						lastEvent = time.Now()
+						debounce.Reset(changeDebounce) // Build only after the last event of a burst
+					case <-debounce.C:
+						cancelBuild() // The in-flight build (if any) is outdated now
+						ctx, cancel := context.WithCancel(buildsCtx)
+						cancelBuild = cancel
+						prevBuildDone := buildDone
+						buildDone = make(chan struct{})
+						go func(ctx context.Context, prevBuildDone <-chan struct{}, buildDone chan<- struct{}) {
+							defer close(buildDone)
+							<-prevBuildDone // Only one build at a time (the previous one was cancelled, so it should be fast)
+							if ctx.Err() == nil {
+								r.rendererSwapChild(ctx, runCmdF, buildDir)
+							}
+						}(ctx, prevBuildDone, buildDone)
 					case err, ok := <-watcher.Errors:
 						if !ok {
 							return
@@ -59,7 +103,7 @@ func (r *Renderer) runRenderer(runCmdF func() *exec.Cmd, watchFiles []string) er
 			}()
 
 			for _, matchedFile := range watchFiles {
-				err = watcher.Add(matchedFile)
+				err = filter.addWatch(watcher, matchedFile)
 				if err != nil {
 					log.Println("Error watching file", matchedFile, "-", err)
 				}
@@ -67,33 +111,61 @@ func (r *Renderer) runRenderer(runCmdF func() *exec.Cmd, watchFiles []string) er
 		}
 	}
 
-	return ebiten.RunGame(rendererEbitenGame{r}) // blocks until the window is closed
+	err := ebiten.RunGame(rendererEbitenGame{r}) // blocks until the window is closed
+	if errors.Is(err, errExitRequested) {
+		err = nil
+	}
+	return err
+}
+
+// errExitRequested stops the renderer as if the window was closed
+var errExitRequested = errors.New("exit requested")
+
+// forcedExitTimeout is how long to wait for the renderer to stop after an interrupt before exiting anyway
+const forcedExitTimeout = 10 * time.Second
+
+// exitOnSignals stops the renderer (like closing the window) when the process is interrupted, exiting right away (still
+// stopping the new code) on the second interrupt or if it takes too long. The returned function stops listening.
+func (r *Renderer) exitOnSignals() func() {
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, signals()...)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-sigs:
+			log.Println("[DevRenderer] Received", sig, "- exiting")
+			r.exitRequested.Store(true) // See rendererEbitenGame.Update
+		case <-done:
+			return
+		}
+		select {
+		case <-sigs:
+		case <-time.After(forcedExitTimeout):
+		case <-done:
+			return
+		}
+		log.Println("[DevRenderer] Forcing exit")
+		r.children.shutdown(time.Second)
+		os.Exit(1)
+	}()
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+	}
 }
 
 func (r *Renderer) runChild(requestedAddress string) error {
 	// Listen for signals
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, signals()...)
-	// Set up a remote service that the parent renderer will connect to view the new SDF
-	service := internal.NewDevRendererService(r.impl, done)
-	service.HandleHTTP(rpc.DefaultRPCPath, rpc.DefaultDebugPath)
-	// TODO: Use service.ServeConn() on a pipe to the parent, avoiding using ports (must be as cross-platform as possible)
-	listener, err := net.Listen("tcp", requestedAddress) // Close() called on srv.Close()
+	// Connect to the parent renderer, which will request renders of the new SDF through this connection
+	conn, err := internal.ChildConnect(requestedAddress)
 	if err != nil {
 		return err
 	}
-	srv := &http.Server{Addr: listener.Addr().String(), Handler: http.DefaultServeMux}
-	defer func(srv *http.Server) {
-		err := srv.Close()
-		if err != nil {
-			log.Println("[DevRenderer] srv.Close error:", err)
-		}
-	}(srv)
+	service := internal.NewDevRendererService(r.impl, done)
 	go func() {
-		err := srv.Serve(listener)
-		if err != nil {
-			log.Println("[DevRenderer] srv.Serve error:", err)
-		}
+		service.ServeConn(conn) // Blocks until the parent disconnects (and closes conn)
 		done <- syscall.SIGKILL
 	}()
 	log.Println("[DevRenderer] Child service ready...")
@@ -102,85 +174,351 @@ func (r *Renderer) runChild(requestedAddress string) error {
 	return nil
 }
 
-func (r *Renderer) rendererSwapChild(runCmd *exec.Cmd, runCmdF func() *exec.Cmd) *exec.Cmd {
-	r.implLock.Lock() // No more renders until we swapped the implementation
-	defer r.implLock.Unlock()
-	//log.Println("[DevRenderer] r.implLock acquired!")
-	// 1. Gracefully close the previous command
-	if runCmd != nil {
-		log.Println("[DevRenderer] Closing previous child process")
-		if rend, ok := r.impl.(*rendererClient); ok {
-			err := rend.Shutdown(5 * time.Second)
-			if err != nil {
-				log.Println("[DevRenderer] Closing previous child process ERROR:", err, "(the child will probably keep running in background)")
+// rendererSwapChild compiles and runs the new code, swapping the implementation once it connects. The previous code keeps
+// rendering while building (and if the new code is broken), and the build is aborted as soon as ctx is cancelled (a newer
+// change arrived). The code is compiled to buildDir and then run, unless a custom command to do both is given (runCmdF).
+func (r *Renderer) rendererSwapChild(ctx context.Context, runCmdF func() *exec.Cmd, buildDir string) {
+	r.setChildStatus(childStatusBuilding, nil)
+	output := &childOutput{}
+	// 1. Compile the new code (unless the custom command does it)
+	var runCmd *exec.Cmd
+	var binaryPath string
+	var buildTime time.Duration
+	if runCmdF != nil {
+		log.Println("[DevRenderer] Compiling and running new code")
+		runCmd = runCmdF()
+	} else {
+		log.Println("[DevRenderer] Compiling new code")
+		buildStart := time.Now()
+		var err error
+		binaryPath, err = r.buildChild(ctx, buildDir, output)
+		if err != nil {
+			if ctx.Err() != nil {
+				log.Println("[DevRenderer] Cancelled building outdated code")
+				return // The newer build will update the status
+			}
+			log.Println("[DevRenderer] Build error:", err)
+			errs := parseBuildErrors(output.String())
+			if len(errs) == 0 {
+				errs = []string{err.Error()}
 			}
+			r.setChildStatus(childStatusFailed, errs)
+			return
 		}
+		buildTime = time.Since(buildStart)
+		log.Println("[DevRenderer] Compiled new code in", buildTime, "- running it")
+		r.setChildStatus(childStatusStarting, nil)
+		runCmd = exec.Command(binaryPath)
 	}
-	log.Println("[DevRenderer] Compiling and running new code")
-	// 2. Get a random free port to ask the child to listen on (it might not be free when the process starts, but ¯\_(ツ)_/¯)
-	tmpL, err := net.Listen("tcp", ":0")
+	startStart := time.Now()
+	// 2. Prepare the connection the child will use to reach us (pipes, Unix socket or loopback TCP, in that order).
+	// Pipes are not used with custom commands, as they may not pass them on to the child (e.g. "go run").
+	transport, err := internal.NewParentTransport(runCmdF == nil)
 	if err != nil {
-		log.Println("[DevRenderer] net.Listen error:", err)
-		return nil
-	}
-	requestedFreeAddr := tmpL.Addr().String()
-	err = tmpL.Close()
-	if err != nil {
-		log.Println("[DevRenderer] tmpL.Close error:", err)
-		return nil
+		log.Println("[DevRenderer] internal.NewParentTransport error:", err)
+		r.removeChildBinary(binaryPath)
+		r.setChildStatus(childStatusFailed, []string{err.Error()})
+		return
 	}
 	// 3. Configure the process and start it in the background
-	runCmd = runCmdF()
-	runCmd.Env = append(os.Environ(), requestedAddressEnvKey+"="+requestedFreeAddr)
-	runCmd.Stdout = os.Stdout // Merge stdout
-	runCmd.Stderr = os.Stderr // Merge stderr
-	err = runCmd.Start()
+	runCmd.Stdout = os.Stdout                         // Merge stdout
+	runCmd.Stderr = io.MultiWriter(os.Stderr, output) // Merge stderr, keeping it to show errors on screen
+	setChildProcessGroup(runCmd)
+	err = transport.Start(runCmd, requestedAddressEnvKey)
 	if err != nil {
 		log.Println("[DevRenderer] runCmd.Start error:", err)
-		return nil
+		if err2 := transport.Close(); err2 != nil {
+			log.Println("[DevRenderer] transport.Close error:", err2)
+		}
+		r.removeChildBinary(binaryPath)
+		r.setChildStatus(childStatusFailed, []string{err.Error()})
+		return
 	}
-	// Note that in case of "go run ...", a new process is forked after successful compilation and the runCmd PID will die.
-	startupFinished := make(chan *os.ProcessState) // true if success
+	// Note that custom commands like "go run ..." fork a new process after compiling, so the runCmd PID is not the child's.
+	exited := make(chan struct{}) // Closed when the process exits (runCmd.ProcessState is available then)
+	child := &childProcess{cmd: runCmd, exited: exited}
 	go func() {
-		ps, err2 := runCmd.Process.Wait()
-		if err2 != nil {
-			log.Println("[DevRenderer] runCmd error:", err2)
-		}
-		select {
-		case startupFinished <- ps:
-		case <-time.After(time.Minute): // Some timeout for detecting failed build/run to let this goroutine die
+		_ = runCmd.Wait() // Also waits for all the output to be copied
+		r.children.remove(child)
+		r.removeChildBinary(binaryPath)
+		close(exited)
+	}()
+	if !r.children.add(child) { // The renderer is exiting
+		if err = killChildProcess(runCmd); err != nil {
+			log.Println("[DevRenderer] killChildProcess error:", err)
 		}
-		close(startupFinished)
+		// Let it fail to connect normally
+	}
+	// 4. Wait for it to connect (once compiled and started), checking with exponential backoff to relax on errors.
+	log.Println("[DevRenderer] Waiting for new code to connect (using " + transport.Kind() + " transport)...")
+	accepted := make(chan acceptResult, 1)
+	go func() {
+		conn, err := transport.Accept()
+		accepted <- acceptResult{conn, err}
 	}()
-	// 4. Connect to it as fast as possible, with exponential backoff to relax on errors.
-	log.Println("[DevRenderer] Trying to connect to new code with exponential backoff...")
+	acceptDone := false
+	var exitState *os.ProcessState // Set if the process exited before connecting
 	r.backOff.Reset()
 	err = backoff.RetryNotify(func() error {
-		dialHTTP, err := rpc.DialHTTP("tcp", requestedFreeAddr)
-		if err != nil {
+		var res acceptResult
+		select {
+		case res = <-accepted:
+			acceptDone = true
+		default:
 			select {
-			case ps, ok := <-startupFinished:
-				if ok && !ps.Success() {
-					err2 := backoff.Permanent(fmt.Errorf("new code crashed (pid " + strconv.Itoa(runCmd.Process.Pid) +
-						"), fix errors: " + ps.String()))
-					return err2
-				}
+			case <-exited:
+				exitState = runCmd.ProcessState
+				return backoff.Permanent(fmt.Errorf("new code exited before connecting (pid " + strconv.Itoa(runCmd.Process.Pid) +
+					"), fix errors: " + exitState.String()))
 			default: // Do not block checking if process success
 			}
-			return err
+			return errors.New("new code is not ready yet")
 		}
-		remoteRenderer := newDevRendererClient(dialHTTP)
-		// 4.1. Swap the renderer on success
-		r.impl = remoteRenderer
+		if res.err != nil {
+			return backoff.Permanent(fmt.Errorf("new code could not connect: %w", res.err))
+		}
+		remoteRenderer, err := newDevRendererClient(rpc.NewClient(res.conn), r.compressFrames)
+		if err != nil { // Incompatible child (it will exit as the connection is closed)
+			return backoff.Permanent(err)
+		}
+		// 4.1. Swap the renderer on success (unless a newer change arrived meanwhile)
+		r.implLock.Lock()
+		if ctx.Err() != nil {
+			r.implLock.Unlock()
+			r.closeChild(remoteRenderer.(*rendererClient), time.Second)
+			return backoff.Permanent(ctx.Err())
+		}
+		toClose := r.swapImpl(remoteRenderer.(*rendererClient))
+		r.implStateLock.Lock()
 		r.implState.ColorMode = r.implState.ColorMode % r.impl.ColorModes() // Use a valid color mode always
-		r.rerender()                                                        // Render the new SDF!!!
+		r.implStateLock.Unlock()
+		r.implLock.Unlock()
+		r.children.connected(child, remoteRenderer.(*rendererClient))
+		r.setChildStatus(childStatusConnected, nil)
+		r.setChildTimes(buildTime, time.Since(startStart))
+		go r.watchChild(remoteRenderer.(*rendererClient), runCmd, exited, output)
+		r.rerender() // Render the new SDF!!!
+		// 4.2. Gracefully close the previous child that is no longer needed (see swapImpl)
+		if toClose != nil {
+			log.Println("[DevRenderer] Closing previous child process")
+			go r.closeChild(toClose, 5*time.Second)
+		}
 		return nil
-	}, r.backOff, func(err error, duration time.Duration) {
+	}, backoff.WithContext(r.backOff, ctx), func(err error, duration time.Duration) {
 		log.Println("[DevRenderer] connection error:", err, "- retrying in:", duration)
 	})
 	if err != nil {
-		log.Println("[DevRenderer] backoff.RetryNotify gave up on connecting, with error:", err)
-		return runCmd
+		cancelled := ctx.Err() != nil
+		if cancelled {
+			log.Println("[DevRenderer] Cancelled building outdated code")
+			select {
+			case <-exited:
+			default:
+				if err2 := killChildProcess(runCmd); err2 != nil {
+					log.Println("[DevRenderer] killChildProcess error:", err2)
+				}
+			}
+		} else {
+			log.Println("[DevRenderer] backoff.RetryNotify gave up on connecting, with error:", err)
+		}
+		if !acceptDone {
+			if err2 := transport.Close(); err2 != nil { // Stop waiting for the connection
+				log.Println("[DevRenderer] transport.Close error:", err2)
+			}
+			if res := <-accepted; res.conn != nil { // Connected too late
+				_ = res.conn.Close()
+			}
+		}
+		if cancelled {
+			return // The newer build will update the status
+		}
+		if exitState == nil {
+			select { // Give some time for the process to exit and for its output to be fully copied
+			case <-exited:
+				exitState = runCmd.ProcessState
+			case <-time.After(time.Second):
+			}
+		}
+		errs := []string{err.Error()}
+		if exitState != nil && !exitState.Success() { // Show the compiler errors or panic instead
+			if buildErrs := parseBuildErrors(output.String()); len(buildErrs) > 0 {
+				errs = buildErrs
+			}
+		}
+		r.setChildStatus(childStatusFailed, errs)
+	}
+}
+
+// buildChild compiles the new code to a new binary inside buildDir (as the previous one may still be running), returning
+// its path. The build is stopped as soon as ctx is cancelled.
+func (r *Renderer) buildChild(ctx context.Context, buildDir string, output io.Writer) (string, error) {
+	pattern := "child-*"
+	if runtime.GOOS == "windows" {
+		pattern += ".exe"
+	}
+	binaryFile, err := os.CreateTemp(buildDir, pattern) // Reserve a unique name
+	if err != nil {
+		return "", err
+	}
+	binaryPath := binaryFile.Name()
+	_ = binaryFile.Close()
+	buildCmd := r.buildCmd(binaryPath)
+	buildCmd.Stdout = os.Stdout
+	buildCmd.Stderr = io.MultiWriter(os.Stderr, output)
+	setChildProcessGroup(buildCmd) // Also stop the compiler processes when cancelled
+	if err = buildCmd.Start(); err != nil {
+		r.removeChildBinary(binaryPath)
+		return "", err
+	}
+	buildDone := make(chan error, 1)
+	go func() {
+		buildDone <- buildCmd.Wait()
+	}()
+	select {
+	case err = <-buildDone:
+	case <-ctx.Done():
+		if err2 := killChildProcess(buildCmd); err2 != nil {
+			log.Println("[DevRenderer] killChildProcess error:", err2)
+		}
+		<-buildDone
+		err = ctx.Err()
+	}
+	if err != nil {
+		r.removeChildBinary(binaryPath)
+		return "", err
+	}
+	return binaryPath, nil
+}
+
+// removeChildBinary deletes a binary built by buildChild once it is no longer needed (does nothing for "")
+func (r *Renderer) removeChildBinary(binaryPath string) {
+	if binaryPath == "" {
+		return
+	}
+	if err := os.Remove(binaryPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Println("[DevRenderer] Binary removal error:", err)
+	}
+}
+
+// closeChild stops the child gracefully (without considering it a crash) and closes the connection to it
+func (r *Renderer) closeChild(rend *rendererClient, timeout time.Duration) {
+	rend.Stop() // Not a crash
+	if err := rend.Shutdown(timeout); err != nil {
+		log.Println("[DevRenderer] Closing previous child process ERROR:", err, "(the child will probably keep running in background)")
+	}
+	if err := rend.Close(); err != nil {
+		log.Println("[DevRenderer] Closing previous child connection ERROR:", err)
+	}
+}
+
+// acceptResult is the result of waiting for a new child to connect
+type acceptResult struct {
+	conn io.ReadWriteCloser
+	err  error
+}
+
+// swapImpl makes the new child the current implementation, returning the previous child to close (if any). The previous
+// implementation is kept as the fallback if it passed the health check (replacing the older fallback), as the new code
+// may still crash. It must be called with the implLock held.
+func (r *Renderer) swapImpl(client *rendererClient) *rendererClient {
+	prev, prevHealthy := r.impl, r.implHealthy
+	r.impl, r.implHealthy = client, false
+	toClose := prev
+	if prevHealthy {
+		toClose, r.fallbackImpl = r.fallbackImpl, prev
+	}
+	if rend, ok := toClose.(*rendererClient); ok {
+		return rend
+	}
+	return nil // The initial implementation is always kept
+}
+
+// markHealthy records that the child passed the health check, so that it will be the fallback of the next one.
+func (r *Renderer) markHealthy(client *rendererClient) bool {
+	r.implLock.Lock()
+	defer r.implLock.Unlock()
+	if r.impl != internal.DevRendererImpl(client) || r.implHealthy {
+		return false
+	}
+	r.implHealthy = true
+	return true
+}
+
+// fallBack stops using the crashed child, returning true if it was the current implementation (replaced by the last
+// working one, see swapImpl).
+func (r *Renderer) fallBack(client *rendererClient) bool {
+	r.implLock.Lock()
+	defer r.implLock.Unlock()
+	if r.fallbackImpl == internal.DevRendererImpl(client) { // The fallback itself crashed
+		r.fallbackImpl = r.initialImpl
+	}
+	if r.impl != internal.DevRendererImpl(client) {
+		return false
+	}
+	r.impl, r.implHealthy = r.fallbackImpl, true
+	r.fallbackImpl = r.initialImpl
+	r.implStateLock.Lock()
+	r.implState.ColorMode = r.implState.ColorMode % r.impl.ColorModes() // Use a valid color mode always
+	r.implStateLock.Unlock()
+	return true
+}
+
+// watchChild checks the health of the new code until it is stopped, falling back to the last working code if it
+// crashes, stops responding or gets stuck rendering. It passes the health check once it completes a render.
+func (r *Renderer) watchChild(client *rendererClient, runCmd *exec.Cmd, exited <-chan struct{}, output *childOutput) {
+	ticker := time.NewTicker(r.watchdogEvery)
+	defer ticker.Stop()
+	var reason string
+	var details []string
+	healthy := false
+	for reason == "" {
+		select {
+		case <-client.closed:
+			return
+		case <-exited:
+			reason = "new code exited unexpectedly: " + runCmd.ProcessState.String()
+			details = parseCrash(output.String())
+		case <-ticker.C:
+			res, err := client.Ping(r.watchdogTimeout)
+			if err != nil {
+				reason = "new code stopped responding: " + err.Error()
+			} else if res.RenderStalled > r.watchdogTimeout {
+				reason = "new code got stuck rendering for " + res.RenderStalled.Round(time.Second).String()
+			} else if !healthy && client.rendered.Load() {
+				healthy = true
+				if r.markHealthy(client) {
+					log.Println("[DevRenderer] Watchdog: new code passed the health check")
+				}
+			}
+		}
+		select {
+		case <-client.closed: // Stopped on purpose while checking
+			return
+		default:
+		}
+	}
+	log.Println("[DevRenderer] Watchdog:", reason, "- falling back to the last working code")
+	// Try to stop it gracefully first, then make sure no calls are left in progress (to be able to swap the renderer)
+	client.Stop()
+	select {
+	case <-exited:
+	default:
+		if err := client.Shutdown(time.Second); err != nil {
+			log.Println("[DevRenderer] Watchdog: shutdown error:", err)
+		}
+	}
+	if err := client.Close(); err != nil {
+		log.Println("[DevRenderer] Watchdog: close error:", err)
+	}
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		if err := killChildProcess(runCmd); err != nil {
+			log.Println("[DevRenderer] Watchdog: kill error:", err)
+		}
+	}
+	if r.fallBack(client) {
+		r.setChildStatus(childStatusCrashed, append([]string{reason}, details...))
+		r.rerender()
 	}
-	return runCmd
 }
@@ -0,0 +1,33 @@
+//go:build linux
+// +build linux
+
+package ui
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setChildProcessGroup starts the command in a new process group, to be able to kill all the processes it starts
+// (like the compiler or the binary started by "go run").
+func setChildProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// killChildProcess kills the started command and all the processes in its group (see setChildProcessGroup), ignoring
+// already finished processes
+func killChildProcess(cmd *exec.Cmd) error {
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+		err = cmd.Process.Kill() // Not a process group leader?
+		if errors.Is(err, os.ErrProcessDone) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+//go:build !linux
+// +build !linux
+
+package ui
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// setChildProcessGroup does nothing on this platform (see the Linux version)
+func setChildProcessGroup(_ *exec.Cmd) {
+}
+
+// killChildProcess kills the started command (but not the processes it started, if any), ignoring already finished
+// processes
+func killChildProcess(cmd *exec.Cmd) error {
+	err := cmd.Process.Kill()
+	if errors.Is(err, os.ErrProcessDone) {
+		return nil
+	}
+	return err
+}
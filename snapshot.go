@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"github.com/deadsy/sdfx/vec/v2i"
+	"image/png"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// snapshotEnvKey is the environment variable that enables the snapshot mode: instead of opening the UI, Run renders the
+// SDF to the PNG file at the given path and returns (e.g. SDFX_UI_SNAPSHOT=part.png go run .).
+const snapshotEnvKey = "SDFX_UI_SNAPSHOT"
+
+// snapshotSizeEnvKey optionally configures the "virtual screen" size for the snapshot mode (e.g. 1280x720).
+// The image is rendered with the same ResInv as the UI would, so its size is the screen size divided by ResInv.
+const snapshotSizeEnvKey = "SDFX_UI_SNAPSHOT_SIZE"
+
+var defaultSnapshotSize = v2i.Vec{X: 1920, Y: 1080}
+
+// runSnapshot renders the SDF with the current state to a PNG file, without opening a window.
+// The state saved by the UI is not loaded, so that snapshots only depend on the code (e.g. for batch jobs).
+func (r *Renderer) runSnapshot(filePath string) error {
+	screenSize := defaultSnapshotSize
+	if sizeStr := os.Getenv(snapshotSizeEnvKey); sizeStr != "" {
+		var err error
+		screenSize, err = parseSnapshotSize(sizeStr)
+		if err != nil {
+			return err
+		}
+	}
+	r.implStateLock.RLock()
+	resInv := r.implState.ResInv
+	r.implStateLock.RUnlock()
+	renderSize := v2i.Vec{X: screenSize.X / resInv, Y: screenSize.Y / resInv}
+	renderStartTime := time.Now()
+	img, err := r.RenderImage(context.Background(), renderSize.X, renderSize.Y)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	err = png.Encode(f, img)
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return err
+	}
+	log.Println("[DevRenderer] Snapshot", filePath, "rendered in", time.Since(renderStartTime))
+	return nil
+}
+
+// parseSnapshotSize parses sizes formatted as <width>x<height>
+func parseSnapshotSize(sizeStr string) (v2i.Vec, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(sizeStr)), "x")
+	if len(parts) != 2 {
+		return v2i.Vec{}, errors.New("invalid snapshot size " + strconv.Quote(sizeStr) + ", expected <width>x<height>")
+	}
+	width, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return v2i.Vec{}, err
+	}
+	height, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return v2i.Vec{}, err
+	}
+	if width <= 0 || height <= 0 {
+		return v2i.Vec{}, errors.New("invalid snapshot size " + strconv.Quote(sizeStr) + ", must be positive")
+	}
+	return v2i.Vec{X: width, Y: height}, nil
+}
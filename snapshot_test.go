@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"github.com/deadsy/sdfx/sdf"
+	"github.com/deadsy/sdfx/vec/v2i"
+	v3 "github.com/deadsy/sdfx/vec/v3"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_parseSnapshotSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    v2i.Vec
+		wantErr bool
+	}{
+		{in: "1280x720", want: v2i.Vec{X: 1280, Y: 720}},
+		{in: " 64X32 ", want: v2i.Vec{X: 64, Y: 32}},
+		{in: "1280", wantErr: true},
+		{in: "0x10", wantErr: true},
+		{in: "axb", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseSnapshotSize(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSnapshotSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSnapshotSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderer_runSnapshot(t *testing.T) {
+	t.Setenv(snapshotSizeEnvKey, "128x64")
+	s, _ := sdf.Box3D(v3.Vec{X: 1, Y: 1, Z: 1}, 0.2)
+	filePath := filepath.Join(t.TempDir(), "snapshot.png")
+	err := NewRenderer(s, OptMResInv(2)).runSnapshot(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 64 || img.Bounds().Dy() != 32 {
+		t.Fatalf("unexpected snapshot size %v", img.Bounds())
+	}
+}
+
+func TestRenderer_runSnapshotIgnoresState(t *testing.T) {
+	t.Setenv(snapshotSizeEnvKey, "128x64")
+	s, _ := sdf.Box3D(v3.Vec{X: 1, Y: 1, Z: 1}, 0.2)
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	saved := NewRenderer(s, OptMStateFile(stateFile), OptMResInv(4))
+	saved.implState.CamDist = 100
+	saved.saveState()
+	r := NewRenderer(s, OptMStateFile(stateFile), OptMResInv(2))
+	camDist := r.implState.CamDist
+	filePath := filepath.Join(t.TempDir(), "snapshot.png")
+	if err := r.runSnapshot(filePath); err != nil {
+		t.Fatal(err)
+	}
+	if r.implState.ResInv != 2 || r.implState.CamDist != camDist {
+		t.Fatalf("the snapshot should not load the saved state, got %#v", r.implState)
+	}
+	if stats := r.ImageStats(); stats.Pixels != 64*32 {
+		t.Fatalf("expected the snapshot to use the configured resolution, rendered %d pixels", stats.Pixels)
+	}
+}
@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/Yeicor/sdfx-ui/internal"
+	"sync"
+	"time"
+)
+
+// RenderStats are the statistics of the latest render (or the one in progress), see Renderer.Stats and
+// Renderer.ImageStats.
+type RenderStats struct {
+	Pixels     int64         // The number of pixels of the render (at the current resolution, plus the refined ones)
+	PixelsDone int64         // The number of pixels already rendered
+	Elapsed    time.Duration // The time spent rendering so far (or the total time once it stopped)
+	Rendering  bool          // Whether the render is still in progress
+	Finished   bool          // Whether the render completed successfully (not cancelled or failed)
+	Rays       int64         // The number of rays cast, one or more per pixel when supersampling (SDF3 raycasting only)
+	Steps      int64         // The total raymarching steps of all rays
+	Misses     int64         // The rays that did not hit the surface
+	Errors     int64         // The rays that ran out of steps before hitting the surface (shown with the error color)
+}
+
+// Progress returns the fraction of the render that is done, from 0 to 1
+func (s RenderStats) Progress() float64 {
+	if s.Pixels == 0 {
+		return 0
+	}
+	return float64(s.PixelsDone) / float64(s.Pixels)
+}
+
+// PixelsPerSecond returns the average render speed
+func (s RenderStats) PixelsPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.PixelsDone) / s.Elapsed.Seconds()
+}
+
+// ETA returns the estimated time left to complete the render (0 if unknown or not rendering)
+func (s RenderStats) ETA() time.Duration {
+	if !s.Rendering || s.PixelsDone == 0 {
+		return 0
+	}
+	return time.Duration(float64(s.Elapsed) * float64(s.Pixels-s.PixelsDone) / float64(s.PixelsDone))
+}
+
+// AvgSteps returns the average raymarching steps of each ray (0 if not raycasting)
+func (s RenderStats) AvgSteps() float64 {
+	if s.Rays == 0 {
+		return 0
+	}
+	return float64(s.Steps) / float64(s.Rays)
+}
+
+// String formats the statistics to be shown on screen
+func (s RenderStats) String() string {
+	state := "done"
+	if s.Rendering {
+		state = "ETA " + s.ETA().Round(time.Millisecond).String()
+	} else if !s.Finished {
+		state = "stopped"
+	}
+	res := fmt.Sprintf("Render: %.1f%% (%d/%d px)\nTime: %s (%s)\nSpeed: %.1fk px/s",
+		100*s.Progress(), s.PixelsDone, s.Pixels, s.Elapsed.Round(time.Millisecond), state, s.PixelsPerSecond()/1000)
+	if s.Rays > 0 {
+		res += fmt.Sprintf("\nSteps/ray: %.1f\nMisses: %d (%.1f%%)\nErrors: %d (%.1f%%)", s.AvgSteps(),
+			s.Misses, 100*float64(s.Misses)/float64(s.Rays), s.Errors, 100*float64(s.Errors)/float64(s.Rays))
+	}
+	return res
+}
+
+// Stats returns the statistics of the latest render shown on screen (or the one in progress).
+func (r *Renderer) Stats() RenderStats {
+	return r.stats.get()
+}
+
+// ImageStats returns the statistics of the latest Renderer.RenderImage (or the one in progress), which may be useful
+// for benchmarks.
+func (r *Renderer) ImageStats() RenderStats {
+	return r.imageStats.get()
+}
+
+// renderStatsTracker keeps the statistics of the latest render
+type renderStatsTracker struct {
+	lock      sync.Mutex
+	counters  *internal.RenderStats
+	start     time.Time
+	elapsed   time.Duration // Only set once the render stopped
+	rendering bool
+	finished  bool
+}
+
+// begin starts tracking a new render, returning where the implementation should count its work
+func (t *renderStatsTracker) begin() *internal.RenderStats {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.counters = &internal.RenderStats{}
+	t.start = time.Now()
+	t.elapsed = 0
+	t.rendering = true
+	t.finished = false
+	return t.counters
+}
+
+// end marks the latest render as stopped
+func (t *renderStatsTracker) end(finished bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.elapsed = time.Since(t.start)
+	t.rendering = false
+	t.finished = finished
+}
+
+func (t *renderStatsTracker) get() RenderStats {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.counters == nil {
+		return RenderStats{} // Nothing rendered yet
+	}
+	snapshot := t.counters.Snapshot()
+	res := RenderStats{
+		Pixels:     snapshot.Pixels,
+		PixelsDone: snapshot.PixelsDone,
+		Elapsed:    t.elapsed,
+		Rendering:  t.rendering,
+		Finished:   t.finished,
+		Rays:       snapshot.Rays,
+		Steps:      snapshot.Steps,
+		Misses:     snapshot.Misses,
+		Errors:     snapshot.Errors,
+	}
+	if t.rendering {
+		res.Elapsed = time.Since(t.start)
+	}
+	return res
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"context"
+	"github.com/deadsy/sdfx/sdf"
+	v3 "github.com/deadsy/sdfx/vec/v3"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderer_Stats(t *testing.T) {
+	s, _ := sdf.Box3D(v3.Vec{X: 1, Y: 1, Z: 1}, 0.2)
+	r := NewRenderer(s)
+	if stats := r.Stats(); stats.Pixels != 0 || stats.Rendering {
+		t.Fatalf("unexpected statistics before rendering: %+v", stats)
+	}
+	if _, err := r.RenderImage(context.Background(), 32, 16); err != nil {
+		t.Fatal(err)
+	}
+	if stats := r.Stats(); stats.Pixels != 0 {
+		t.Fatalf("headless renders should not modify the statistics shown on screen: %+v", stats)
+	}
+	stats := r.ImageStats()
+	if stats.Pixels != 32*16 || stats.PixelsDone != stats.Pixels || stats.Rendering || !stats.Finished {
+		t.Fatalf("unexpected pixel statistics: %+v", stats)
+	}
+	if stats.Rays != stats.Pixels || stats.AvgSteps() < 1 || stats.Misses == 0 || stats.Misses == stats.Rays {
+		t.Fatalf("unexpected ray statistics: %+v", stats)
+	}
+	if stats.Progress() != 1 || stats.ETA() != 0 || !strings.Contains(stats.String(), "Steps/ray") {
+		t.Fatalf("unexpected derived statistics: %+v\n%s", stats, stats.String())
+	}
+}
+
+func TestRenderStats_ETA(t *testing.T) {
+	stats := RenderStats{Pixels: 100, PixelsDone: 25, Elapsed: time.Second, Rendering: true}
+	if stats.ETA() != 3*time.Second || stats.PixelsPerSecond() != 25 {
+		t.Fatalf("unexpected ETA %s or speed %f", stats.ETA(), stats.PixelsPerSecond())
+	}
+	if strings.Contains(stats.String(), "Steps/ray") {
+		t.Fatalf("ray statistics should be hidden if not raycasting:\n%s", stats.String())
+	}
+}
@@ -0,0 +1,157 @@
+// Package uitest provides golden-image regression testing for SDF2/SDF3 models, rendered with the same renderers used
+// by the interactive UI.
+//
+// Golden images are stored as PNG files and compared with a tolerance. Run the tests with the UITEST_UPDATE=1
+// environment variable to create or overwrite them (e.g. UITEST_UPDATE=1 go test ./...). On failure, the actual render
+// and a diff image are saved next to the golden image for inspection.
+package uitest
+
+import (
+	"context"
+	"fmt"
+	"github.com/Yeicor/sdfx-ui"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// updateEnvKey is the environment variable that updates the golden images instead of comparing against them (an
+// environment variable instead of a flag, to avoid conflicts with the flags of the packages that import uitest)
+const updateEnvKey = "UITEST_UPDATE"
+
+// updating returns true if the golden images should be updated (see updateEnvKey)
+func updating() bool {
+	update, _ := strconv.ParseBool(os.Getenv(updateEnvKey))
+	return update
+}
+
+// Golden configures how golden images are rendered and compared.
+type Golden struct {
+	Dir           string  // The directory that stores the golden images
+	Width, Height int     // The size of the rendered images
+	Tolerance     uint8   // The maximum difference of any color channel for two pixels to be considered equal
+	MaxDiffRatio  float64 // The ratio of pixels that may differ before failing (in [0, 1])
+}
+
+// NewGolden returns the default configuration: 256x256 images stored in testdata, with a small tolerance.
+func NewGolden() *Golden {
+	return &Golden{
+		Dir:          "testdata",
+		Width:        256,
+		Height:       256,
+		Tolerance:    8,
+		MaxDiffRatio: 0.001,
+	}
+}
+
+// Assert renders anySDF (SDF2 or SDF3) using the default configuration and compares it against <Dir>/<name>.png.
+func Assert(t testing.TB, name string, anySDF interface{}, opts ...ui.Option) {
+	t.Helper()
+	NewGolden().Assert(t, name, anySDF, opts...)
+}
+
+// Assert renders anySDF (SDF2 or SDF3) and compares it against <Dir>/<name>.png.
+// The options are forwarded to ui.NewRenderer, so they may be used to fix the camera or the color mode.
+func (g *Golden) Assert(t testing.TB, name string, anySDF interface{}, opts ...ui.Option) {
+	t.Helper()
+	got, err := ui.NewRenderer(anySDF, opts...).RenderImage(context.Background(), g.Width, g.Height)
+	if err != nil {
+		t.Fatalf("uitest: rendering %s: %v", name, err)
+	}
+	goldenPath := filepath.Join(g.Dir, name+".png")
+	actualPath := filepath.Join(g.Dir, name+".actual.png")
+	diffPath := filepath.Join(g.Dir, name+".diff.png")
+	if updating() {
+		if err = os.MkdirAll(g.Dir, 0755); err != nil {
+			t.Fatalf("uitest: %v", err)
+		}
+		if err = writePNG(goldenPath, got); err != nil {
+			t.Fatalf("uitest: %v", err)
+		}
+		_ = os.Remove(actualPath)
+		_ = os.Remove(diffPath)
+		return
+	}
+	want, err := readPNG(goldenPath)
+	if err != nil {
+		t.Fatalf("uitest: reading golden image (run the tests with "+updateEnvKey+"=1 to create it): %v", err)
+	}
+	diffPixels, diff := compareImages(want, got, g.Tolerance)
+	totalPixels := g.Width * g.Height
+	if diffPixels <= int(g.MaxDiffRatio*float64(totalPixels)) {
+		_ = os.Remove(actualPath)
+		_ = os.Remove(diffPath)
+		return
+	}
+	msg := fmt.Sprintf("uitest: %s differs from the golden image in %d/%d pixels", name, diffPixels, totalPixels)
+	if err = writePNG(actualPath, got); err == nil && diff != nil {
+		err = writePNG(diffPath, diff)
+	}
+	if err != nil {
+		msg += fmt.Sprintf(" (error saving the actual and diff images: %v)", err)
+	} else if diff != nil {
+		msg += fmt.Sprintf(" (see %s and %s)", actualPath, diffPath)
+	} else {
+		msg += fmt.Sprintf(" (see %s)", actualPath)
+	}
+	t.Fatal(msg)
+}
+
+// compareImages returns the number of pixels that differ by more than tolerance in any channel, and an image that
+// highlights them in red over a faded copy of got. If the sizes do not match, all pixels differ and no image is returned.
+func compareImages(want, got image.Image, tolerance uint8) (int, *image.RGBA) {
+	bounds := got.Bounds()
+	if want.Bounds().Size() != bounds.Size() {
+		return bounds.Dx() * bounds.Dy(), nil
+	}
+	wantOffset := want.Bounds().Min.Sub(bounds.Min)
+	diff := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	diffPixels := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c1 := color.RGBAModel.Convert(want.At(x+wantOffset.X, y+wantOffset.Y)).(color.RGBA)
+			c2 := color.RGBAModel.Convert(got.At(x, y)).(color.RGBA)
+			if channelDiff(c1.R, c2.R) > tolerance || channelDiff(c1.G, c2.G) > tolerance ||
+				channelDiff(c1.B, c2.B) > tolerance || channelDiff(c1.A, c2.A) > tolerance {
+				diffPixels++
+				diff.SetRGBA(x-bounds.Min.X, y-bounds.Min.Y, color.RGBA{R: 255, A: 255})
+			} else {
+				gray := uint8((uint16(c2.R) + uint16(c2.G) + uint16(c2.B)) / 3 / 4)
+				diff.SetRGBA(x-bounds.Min.X, y-bounds.Min.Y, color.RGBA{R: gray, G: gray, B: gray, A: 255})
+			}
+		}
+	}
+	return diffPixels, diff
+}
+
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func readPNG(filePath string) (image.Image, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+func writePNG(filePath string, img image.Image) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	err = png.Encode(f, img)
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
@@ -0,0 +1,50 @@
+package uitest
+
+import (
+	"github.com/Yeicor/sdfx-ui"
+	"github.com/deadsy/sdfx/sdf"
+	v2 "github.com/deadsy/sdfx/vec/v2"
+	v3 "github.com/deadsy/sdfx/vec/v3"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestAssert2(t *testing.T) {
+	s := sdf.Box2D(v2.Vec{X: 1, Y: 1}, 0.25)
+	Assert(t, "box2", s)
+}
+
+func TestAssert3(t *testing.T) {
+	s, _ := sdf.Box3D(v3.Vec{X: 1, Y: 1, Z: 1}, 0.2)
+	g := NewGolden()
+	g.Width, g.Height = 128, 96
+	g.Assert(t, "box3", s, ui.OptMColorMode(1))
+}
+
+func Test_compareImages(t *testing.T) {
+	want := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	got := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	got.SetRGBA(1, 1, color.RGBA{R: 5})
+	got.SetRGBA(2, 2, color.RGBA{G: 50})
+	if diffPixels, _ := compareImages(want, got, 10); diffPixels != 1 {
+		t.Fatalf("expected 1 different pixel, got %d", diffPixels)
+	}
+	if diffPixels, _ := compareImages(want, got, 0); diffPixels != 2 {
+		t.Fatalf("expected 2 different pixels, got %d", diffPixels)
+	}
+	if diffPixels, diff := compareImages(want, image.NewRGBA(image.Rect(0, 0, 2, 2)), 0); diffPixels != 4 || diff != nil {
+		t.Fatalf("expected all pixels to differ for different sizes, got %d", diffPixels)
+	}
+}
+
+func Test_updating(t *testing.T) {
+	t.Setenv(updateEnvKey, "0")
+	if updating() {
+		t.Fatalf("expected not to update the golden images")
+	}
+	t.Setenv(updateEnvKey, "1")
+	if !updating() {
+		t.Fatalf("expected to update the golden images")
+	}
+}
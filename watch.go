@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// Default patterns for the files that trigger a recompilation (see OptMWatchPatterns)
+var (
+	defaultWatchInclude = []string{"*.go", "go.mod", "go.sum", "*.ttf", "*.otf"} // Not generated files (like *.stl)
+	defaultWatchExclude = []string{".*", "*~", "#*#", "*.tmp", "*.swp", "___*", "vendor", "node_modules", "testdata"}
+)
+
+// fsWatcher is the common interface of the supported and unsupported file watchers
+type fsWatcher interface {
+	Add(name string) error
+}
+
+// watchFilter decides which file changes should trigger a recompilation.
+type watchFilter struct {
+	include, exclude []string        // Glob patterns matched against the base name of each file or directory
+	explicit         map[string]bool // Files that were explicitly requested are always watched
+}
+
+func newWatchFilter(include, exclude []string, watchFiles []string) *watchFilter {
+	f := &watchFilter{include: include, exclude: exclude, explicit: map[string]bool{}}
+	for _, watchFile := range watchFiles {
+		f.explicit[filepath.Clean(watchFile)] = true
+	}
+	return f
+}
+
+// excluded checks if the file or directory should be ignored (also skipping its contents for directories).
+func (f *watchFilter) excluded(name string) bool {
+	return matchAnyGlob(f.exclude, filepath.Base(name))
+}
+
+// matches checks if a change to the file should trigger a recompilation.
+func (f *watchFilter) matches(name string) bool {
+	name = filepath.Clean(name)
+	if f.explicit[name] {
+		return true
+	}
+	return !f.excluded(name) && matchAnyGlob(f.include, filepath.Base(name))
+}
+
+func matchAnyGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, err := filepath.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// addWatch watches the file, or the directory and all its subdirectories (skipping the excluded ones).
+func (f *watchFilter) addWatch(watcher fsWatcher, root string) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return watcher.Add(root)
+	}
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && f.excluded(path) {
+			return filepath.SkipDir
+		}
+		if err = watcher.Add(path); err != nil {
+			log.Println("[DevRenderer] Error watching directory", path, "-", err)
+		}
+		return nil
+	})
+}
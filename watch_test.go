@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func Test_watchFilter_matches(t *testing.T) {
+	f := newWatchFilter(defaultWatchInclude, defaultWatchExclude, []string{".", "assets/model.stl"})
+	for name, expected := range map[string]bool{
+		"main.go":                   true,
+		"sub/dir/part.go":           true,
+		"go.mod":                    true,
+		"fonts/font.ttf":            true,
+		"assets/model.stl":          true, // Explicit
+		"assets/other.stl":          false,
+		".main.go.swp":              false,
+		"main.go~":                  false,
+		"___go_build_main_go":       false,
+		".idea/workspace.xml":       false,
+		"#main.go#":                 false,
+		"README.md":                 false,
+		"output.png":                false,
+		"examples/spiral/README.md": false,
+	} {
+		if got := f.matches(name); got != expected {
+			t.Errorf("matches(%q) = %v, expected %v", name, got, expected)
+		}
+	}
+}
+
+type recordingWatcher []string
+
+func (w *recordingWatcher) Add(name string) error {
+	*w = append(*w, name)
+	return nil
+}
+
+func Test_watchFilter_addWatch(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a/b", ".git/objects", "vendor/x", "testdata", "c"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file := filepath.Join(root, "c", "main.go")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f := newWatchFilter(defaultWatchInclude, defaultWatchExclude, []string{root})
+	watcher := &recordingWatcher{}
+	if err := f.addWatch(watcher, root); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{root, filepath.Join(root, "a"), filepath.Join(root, "a", "b"), filepath.Join(root, "c")}
+	sort.Strings(*watcher)
+	sort.Strings(expected)
+	if len(*watcher) != len(expected) {
+		t.Fatalf("watched %v, expected %v", *watcher, expected)
+	}
+	for i := range expected {
+		if (*watcher)[i] != expected[i] {
+			t.Fatalf("watched %v, expected %v", *watcher, expected)
+		}
+	}
+	// Files are watched directly
+	watcher = &recordingWatcher{}
+	if err := f.addWatch(watcher, file); err != nil || len(*watcher) != 1 || (*watcher)[0] != file {
+		t.Fatalf("watched %v (error %v), expected only %s", *watcher, err, file)
+	}
+}